@@ -0,0 +1,229 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AddressListSpec defines the desired state of AddressList.
+type AddressListSpec struct {
+	// Entries are the CIDR ranges in the list.
+	// +optional
+	// +listType=atomic
+	Entries []AddressListEntry `json:"entries,omitempty"`
+
+	// Source fetches additional entries from an external source.
+	// Fetched entries are added to Entries.
+	// +optional
+	Source *AddressListSource `json:"source,omitempty"`
+}
+
+// AddressListSource defines an external source of entries.
+type AddressListSource struct {
+	// HTTP fetches entries from an HTTP(S) URL.
+	// +optional
+	HTTP *HTTPAddressListSource `json:"http,omitempty"`
+}
+
+// AddressListSourceFormat is the format of the document published by a source.
+// +kubebuilder:validation:Enum=Plain;AWS;GCP;Azure;GitHub
+type AddressListSourceFormat string
+
+const (
+	// AddressListSourceFormatPlain is a newline- or comma-separated list of CIDRs.
+	// Everything after '#' on a line is a comment.
+	AddressListSourceFormatPlain AddressListSourceFormat = "Plain"
+
+	// AddressListSourceFormatAWS is the AWS ip-ranges.json document.
+	AddressListSourceFormatAWS AddressListSourceFormat = "AWS"
+
+	// AddressListSourceFormatGCP is the Google Cloud cloud.json document. Regions match the scope.
+	AddressListSourceFormatGCP AddressListSourceFormat = "GCP"
+
+	// AddressListSourceFormatAzure is the Azure Service Tags document. Services match the service tag name.
+	AddressListSourceFormatAzure AddressListSourceFormat = "Azure"
+
+	// AddressListSourceFormatGitHub is the GitHub meta document. Services match the keys, e.g. "actions".
+	AddressListSourceFormatGitHub AddressListSourceFormat = "GitHub"
+)
+
+// HTTPAddressListSource fetches CIDRs from a URL.
+type HTTPAddressListSource struct {
+	// URL is the HTTP(S) URL the list is published at. file:// URLs are read from
+	// the operator's address list directory, e.g. a mounted ConfigMap.
+	// +kubebuilder:validation:Pattern=`^(https?|file)://`
+	URL string `json:"url"`
+
+	// Format is the format of the published document.
+	// +optional
+	// +kubebuilder:default=Plain
+	Format AddressListSourceFormat `json:"format,omitempty"`
+
+	// Services limits the entries to the given services, e.g. "CLOUDFRONT" for AWS.
+	// Not supported by the Plain format.
+	// +optional
+	// +listType=atomic
+	Services []string `json:"services,omitempty"`
+
+	// Regions limits the entries to the given regions, e.g. "eu-west-1" for AWS.
+	// Not supported by the Plain and GitHub formats.
+	// +optional
+	// +listType=atomic
+	Regions []string `json:"regions,omitempty"`
+
+	// RefreshInterval is how often the URL is polled.
+	// +optional
+	// +kubebuilder:default="1h"
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+
+	// KeepLastKnownGood keeps the last successfully fetched entries while the
+	// URL is unreachable. When false, fetched entries are dropped on failure.
+	// +optional
+	// +kubebuilder:default=true
+	KeepLastKnownGood *bool `json:"keepLastKnownGood,omitempty"`
+}
+
+// AddressListEntry is a single CIDR range in an AddressList.
+type AddressListEntry struct {
+	// CIDR is an IPv4 or IPv6 CIDR range, e.g. "10.20.30.0/24" or "2001:db8::/64".
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="isCIDR(self)",message="cidr must be a valid CIDR range"
+	CIDR string `json:"cidr"`
+
+	// Description describes what the range is used for.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Owner is the team or person responsible for the range.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+}
+
+// AddressListConsumer identifies a route or gateway that references an AddressList.
+type AddressListConsumer struct {
+	// Kind is the kind of the consumer, e.g. HTTPRoute, GRPCRoute or Gateway.
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the consumer.
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the consumer.
+	Name string `json:"name"`
+}
+
+// AddressListStatus defines the observed state of AddressList.
+type AddressListStatus struct {
+	// ObservedGeneration is the most recent generation observed by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// EntryCount is the number of entries in the list, including fetched entries.
+	// +optional
+	EntryCount int32 `json:"entryCount"`
+
+	// Consumers are the routes and gateways that reference the list.
+	// +optional
+	// +listType=atomic
+	Consumers []AddressListConsumer `json:"consumers,omitempty"`
+
+	// Source is the observed state of the external source.
+	// +optional
+	Source *AddressListSourceStatus `json:"source,omitempty"`
+
+	// Conditions describe the current state of the AddressList.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// AddressListSourceStatus is the observed state of an external source.
+type AddressListSourceStatus struct {
+	// Entries are the CIDRs fetched from the source. A source with more entries
+	// is not synced, as the status would exceed the size limit of an object.
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MaxItems=20000
+	Entries []string `json:"entries,omitempty"`
+
+	// ContentHash is the SHA-256 hash of the fetched entries.
+	// +optional
+	ContentHash string `json:"contentHash,omitempty"`
+
+	// ETag is the entity tag returned by the source on the last successful fetch.
+	// +optional
+	ETag string `json:"etag,omitempty"`
+
+	// LastModified is the Last-Modified header returned by the source on the last successful fetch.
+	// +optional
+	LastModified string `json:"lastModified,omitempty"`
+
+	// LastFetchTime is the time of the last fetch attempt.
+	// +optional
+	LastFetchTime *metav1.Time `json:"lastFetchTime,omitempty"`
+
+	// LastSuccessfulFetchTime is the time of the last successful fetch.
+	// +optional
+	LastSuccessfulFetchTime *metav1.Time `json:"lastSuccessfulFetchTime,omitempty"`
+}
+
+// AddressListConditionSourceSynced indicates whether the entries of the external
+// source were fetched successfully.
+const AddressListConditionSourceSynced = "SourceSynced"
+
+// AddressListSourceMaxEntries is the maximum number of entries fetched from an external
+// source, which keeps the status well below the size limit of an object.
+const AddressListSourceMaxEntries = 20000
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=al
+// +kubebuilder:printcolumn:name="Entries",type=integer,JSONPath=`.status.entryCount`
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="SourceSynced")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// AddressList is a cluster-scoped list of CIDR ranges that can be referenced from the
+// securitypolicies.vitistack.io/lists annotation as "addresslist:<name>".
+type AddressList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec defines the desired state of AddressList
+	// +required
+	Spec AddressListSpec `json:"spec"`
+
+	// status defines the observed state of AddressList
+	// +optional
+	Status AddressListStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AddressListList contains a list of AddressList.
+type AddressListList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AddressList `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AddressList{}, &AddressListList{})
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// addressToken identifies a reserved token in the addresses annotation.
+// Service is only set for "@service:" tokens.
+type addressToken struct {
+	Name    string
+	Service listReference
+}
+
+// parseAddressToken parses a reserved token of the addresses annotation and reports
+// whether the entry is a token. Tokens are "@nodes", "@pod-cidrs" and
+// "@service:namespace/name", where "@service:name" resolves in localNamespace
+// (the namespace of the route).
+func parseAddressToken(entry string, localNamespace string) (addressToken, bool, error) {
+	entry = strings.TrimSpace(entry)
+	if !strings.HasPrefix(entry, "@") {
+		return addressToken{}, false, nil
+	}
+
+	switch entry {
+	case AddressTokenNodes, AddressTokenPodCIDRs:
+		return addressToken{Name: entry}, true, nil
+	}
+
+	reference, found := strings.CutPrefix(entry, AddressTokenServicePrefix)
+	if !found {
+		return addressToken{}, true, fmt.Errorf("unknown token %q, valid tokens: %s, %s, %s<namespace>/<name>",
+			entry, AddressTokenNodes, AddressTokenPodCIDRs, AddressTokenServicePrefix)
+	}
+
+	namespace, name, found := strings.Cut(reference, "/")
+	if !found {
+		namespace, name = localNamespace, reference
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return addressToken{}, true, fmt.Errorf("invalid namespace in token %q: %s", entry, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1035Label(name); len(errs) > 0 {
+		return addressToken{}, true, fmt.Errorf("invalid name in token %q: %s", entry, strings.Join(errs, ", "))
+	}
+
+	return addressToken{
+		Name:    AddressTokenService,
+		Service: listReference{Kind: ServiceKind, Namespace: namespace, Name: name},
+	}, true, nil
+}
+
+// referencesAddressToken reports whether the addresses annotation value of a resource
+// in localNamespace contains one of the target tokens.
+func referencesAddressToken(entries []string, localNamespace string, targets ...addressToken) bool {
+	for _, entry := range entries {
+		token, isToken, err := parseAddressToken(entry, localNamespace)
+		if !isToken || err != nil {
+			continue
+		}
+		for _, target := range targets {
+			if token == target {
+				return true
+			}
+		}
+	}
+	return false
+}
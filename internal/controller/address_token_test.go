@@ -0,0 +1,35 @@
+package controller
+
+import "testing"
+
+func TestParseAddressToken(t *testing.T) {
+	tests := []struct {
+		entry   string
+		want    addressToken
+		isToken bool
+		wantErr bool
+	}{
+		{entry: "10.0.0.0/8"},
+		{entry: "@nodes", want: addressToken{Name: AddressTokenNodes}, isToken: true},
+		{entry: " @pod-cidrs ", want: addressToken{Name: AddressTokenPodCIDRs}, isToken: true},
+		{
+			entry:   "@service:ingress/envoy",
+			want:    addressToken{Name: AddressTokenService, Service: listReference{Kind: ServiceKind, Namespace: "ingress", Name: "envoy"}},
+			isToken: true,
+		},
+		{
+			entry:   "@service:envoy",
+			want:    addressToken{Name: AddressTokenService, Service: listReference{Kind: ServiceKind, Namespace: "tenant", Name: "envoy"}},
+			isToken: true,
+		},
+		{entry: "@service:ingress/Envoy", isToken: true, wantErr: true},
+		{entry: "@node", isToken: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, isToken, err := parseAddressToken(tt.entry, "tenant")
+		if (err != nil) != tt.wantErr || isToken != tt.isToken || got != tt.want {
+			t.Errorf("parseAddressToken(%q) = %+v, %v, %v, want %+v, %v, error %v", tt.entry, got, isToken, err, tt.want, tt.isToken, tt.wantErr)
+		}
+	}
+}
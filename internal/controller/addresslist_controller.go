@@ -0,0 +1,290 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	securitypoliciesv1alpha1 "github.com/vitistack/gatewayapi-securitypolicy-operator/api/v1alpha1"
+)
+
+// AddressListReconciler reconciles an AddressList object
+type AddressListReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	HTTPClient *http.Client
+}
+
+// +kubebuilder:rbac:groups=securitypolicies.vitistack.io,resources=addresslists,verbs=get;list;watch
+// +kubebuilder:rbac:groups=securitypolicies.vitistack.io,resources=addresslists/status,verbs=get;update;patch
+
+// Reconcile keeps the status of an AddressList up to date, fetches entries from
+// its external source and triggers reconciliation of all routes and gateways that
+// reference it when its entries change.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
+func (r *AddressListReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	log.Info("Reconciling AddressList", "AddressList.Name", req.Name)
+
+	// Fetch the AddressList instance
+	var addressList securitypoliciesv1alpha1.AddressList
+	if err := r.Get(ctx, req.NamespacedName, &addressList); err != nil {
+		log.Error(err, "Failed to get AddressList")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Start from the current status to preserve the state of the external source
+	status := *addressList.Status.DeepCopy()
+	status.ObservedGeneration = addressList.Generation
+
+	// Fetch entries from the external source
+	var result ctrl.Result
+	sourceChanged := false
+	if addressList.Spec.Source != nil && addressList.Spec.Source.HTTP != nil {
+		// Reconciles triggered by routes and gateways do not fetch before the refresh interval has passed
+		specChanged := addressList.Generation != addressList.Status.ObservedGeneration
+		refreshInterval := DefaultRemoteListRefreshInterval
+		if addressList.Spec.Source.HTTP.RefreshInterval != nil && addressList.Spec.Source.HTTP.RefreshInterval.Duration > 0 {
+			refreshInterval = addressList.Spec.Source.HTTP.RefreshInterval.Duration
+		}
+		result.RequeueAfter = untilNextFetch(status.Source, refreshInterval, time.Now())
+		if specChanged || result.RequeueAfter == 0 {
+			sourceChanged = r.syncHTTPSource(ctx, addressList.Spec.Source.HTTP, specChanged, &status)
+			result.RequeueAfter = refreshInterval
+		}
+	} else if status.Source != nil {
+		// Drop fetched entries when the source is removed
+		status.Source = nil
+		meta.RemoveStatusCondition(&status.Conditions, securitypoliciesv1alpha1.AddressListConditionSourceSynced)
+		sourceChanged = true
+	}
+
+	// Find all HttpRoutes, GRPCRoutes and Gateways that reference the AddressList
+	consumers, err := listConsumers(ctx, r.Client, listReference{
+		Kind: AddressListKind,
+		Name: addressList.Name,
+	})
+	if err != nil {
+		log.Error(err, "Failed to list consumers of AddressList")
+		return ctrl.Result{}, err
+	}
+
+	// Update status with entry count and consumers
+	status.Consumers = nil
+	for _, consumer := range consumers {
+		status.Consumers = append(status.Consumers, securitypoliciesv1alpha1.AddressListConsumer{
+			Kind:      consumer.Kind,
+			Namespace: consumer.Namespace,
+			Name:      consumer.Name,
+		})
+	}
+	slices.SortFunc(status.Consumers, func(a, b securitypoliciesv1alpha1.AddressListConsumer) int {
+		return cmp.Or(
+			strings.Compare(a.Kind, b.Kind),
+			strings.Compare(a.Namespace, b.Namespace),
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+
+	observed := addressList.DeepCopy()
+	observed.Status = status
+	if cidrs, err := extractCIDRsFromAddressList(observed, nil); err == nil {
+		status.EntryCount = int32(len(cidrs))
+	}
+
+	// The status is written before consumers are notified, so they resolve the new entries
+	entriesChanged := addressList.Generation != addressList.Status.ObservedGeneration || sourceChanged
+	if !reflect.DeepEqual(addressList.Status, status) {
+		deepCopyAddressList := addressList.DeepCopy()
+		addressList.Status = status
+		if err := r.Status().Patch(ctx, &addressList, client.MergeFrom(deepCopyAddressList)); err != nil {
+			log.Error(err, "unable to update AddressList status", "AddressList.Name", addressList.Name)
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Update each consumer to trigger reconciliation if the entries changed
+	if entriesChanged {
+		for _, consumer := range consumers {
+			if err := notifyController(ctx, r.Client, consumer.Object); err != nil {
+				log.Error(err, "Failed to notify "+consumer.Kind, consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+				return ctrl.Result{}, err
+			}
+			log.Info("Patched "+consumer.Kind+" due to AddressList change", consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+		}
+	}
+
+	return result, nil
+}
+
+// syncHTTPSource fetches the entries of an HTTP source into status and reports
+// whether the fetched entries changed. Fetch failures are recorded as a condition.
+// When the spec changed, the list is fetched again even if the document is unchanged,
+// since the URL, format or filters may differ.
+func (r *AddressListReconciler) syncHTTPSource(ctx context.Context, source *securitypoliciesv1alpha1.HTTPAddressListSource, specChanged bool, status *securitypoliciesv1alpha1.AddressListStatus) bool {
+	log := logf.FromContext(ctx)
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	if status.Source == nil {
+		status.Source = &securitypoliciesv1alpha1.AddressListSourceStatus{}
+	}
+	previousHash := status.Source.ContentHash
+	now := metav1.Now()
+	status.Source.LastFetchTime = &now
+
+	etag, lastModified := status.Source.ETag, status.Source.LastModified
+	if specChanged {
+		etag, lastModified = "", ""
+	}
+
+	fetched, err := fetchRemoteList(ctx, httpClient, source, etag, lastModified)
+	reason := "FetchFailed"
+
+	// Larger lists do not fit into the status, the update would fail on every refresh
+	if err == nil && len(fetched.CIDRs) > securitypoliciesv1alpha1.AddressListSourceMaxEntries {
+		reason = "TooManyEntries"
+		err = fmt.Errorf("%s returned %d entries, more than the limit of %d, narrow the source down with services and regions",
+			source.URL, len(fetched.CIDRs), securitypoliciesv1alpha1.AddressListSourceMaxEntries)
+	}
+
+	if err != nil {
+		log.Info("Unable to fetch AddressList source", "URL", source.URL, "Error", err)
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:    securitypoliciesv1alpha1.AddressListConditionSourceSynced,
+			Status:  metav1.ConditionFalse,
+			Reason:  reason,
+			Message: err.Error(),
+		})
+
+		// Drop the last known good entries unless they should be kept
+		if source.KeepLastKnownGood != nil && !*source.KeepLastKnownGood {
+			status.Source.Entries = nil
+			status.Source.ContentHash = ""
+			status.Source.ETag = ""
+			status.Source.LastModified = ""
+		}
+		return status.Source.ContentHash != previousHash
+	}
+
+	if !fetched.NotModified {
+		status.Source.Entries = fetched.CIDRs
+		status.Source.ContentHash = hashCIDRs(fetched.CIDRs)
+		status.Source.ETag = fetched.ETag
+		status.Source.LastModified = fetched.LastModified
+	}
+	status.Source.LastSuccessfulFetchTime = &now
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:    securitypoliciesv1alpha1.AddressListConditionSourceSynced,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Fetched",
+		Message: fmt.Sprintf("fetched %d entries from %s", len(status.Source.Entries), source.URL),
+	})
+
+	return status.Source.ContentHash != previousHash
+}
+
+// untilNextFetch returns the time left until the source of an AddressList is due to be
+// fetched again, or zero if it is due now or has never been fetched.
+func untilNextFetch(sourceStatus *securitypoliciesv1alpha1.AddressListSourceStatus, refreshInterval time.Duration, now time.Time) time.Duration {
+	if sourceStatus == nil || sourceStatus.LastFetchTime == nil {
+		return 0
+	}
+	return max(sourceStatus.LastFetchTime.Add(refreshInterval).Sub(now), 0)
+}
+
+// addressListsForObject maps a route or gateway to the AddressLists referenced in its list or expression annotations.
+func addressListsForObject(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, entry := range listEntries(obj.GetAnnotations(), obj.GetNamespace()) {
+		reference, err := parseListReference(entry, obj.GetNamespace())
+		if err != nil || reference.Kind != AddressListKind {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: reference.Name}})
+	}
+	return requests
+}
+
+// addressListConsumerHandler enqueues the AddressLists referenced by a route or gateway.
+// On updates, the AddressLists referenced before the update are enqueued as well, so
+// a consumer that drops a reference is removed from their status.
+var addressListConsumerHandler = handler.Funcs{
+	CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		enqueueAddressLists(q, addressListsForObject(ctx, e.Object))
+	},
+	UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		enqueueAddressLists(q, addressListsForObject(ctx, e.ObjectOld))
+		enqueueAddressLists(q, addressListsForObject(ctx, e.ObjectNew))
+	},
+	DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		enqueueAddressLists(q, addressListsForObject(ctx, e.Object))
+	},
+	GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		enqueueAddressLists(q, addressListsForObject(ctx, e.Object))
+	},
+}
+
+// enqueueAddressLists adds the requests to the queue, which drops duplicates.
+func enqueueAddressLists(q workqueue.TypedRateLimitingInterface[reconcile.Request], requests []reconcile.Request) {
+	for _, request := range requests {
+		q.Add(request)
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AddressListReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	// Routes and gateways are watched to keep the consumers in status up to date.
+	// Only spec changes of the AddressList itself trigger reconciliation, status
+	// updates are ignored.
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&securitypoliciesv1alpha1.AddressList{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&gatewayv1.HTTPRoute{}, addressListConsumerHandler).
+		Watches(&gatewayv1.GRPCRoute{}, addressListConsumerHandler).
+		Watches(&gatewayv1.Gateway{}, addressListConsumerHandler).
+		Named("addresslist").
+		Complete(r)
+}
@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	securitypoliciesv1alpha1 "github.com/vitistack/gatewayapi-securitypolicy-operator/api/v1alpha1"
+)
+
+func TestAddressListReconcileRespectsRefreshInterval(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_, _ = w.Write([]byte("192.0.2.0/24\n"))
+	}))
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayv1.Install(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := securitypoliciesv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	addressList := &securitypoliciesv1alpha1.AddressList{
+		ObjectMeta: metav1.ObjectMeta{Name: "partners", Generation: 1},
+		Spec: securitypoliciesv1alpha1.AddressListSpec{
+			Source: &securitypoliciesv1alpha1.AddressListSource{
+				HTTP: &securitypoliciesv1alpha1.HTTPAddressListSource{
+					URL:             server.URL,
+					RefreshInterval: &metav1.Duration{Duration: time.Hour},
+				},
+			},
+		},
+	}
+	r := &AddressListReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(addressList).
+			WithStatusSubresource(addressList).Build(),
+		HTTPClient: server.Client(),
+	}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "partners"}}
+
+	result, err := r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 1 || result.RequeueAfter != time.Hour {
+		t.Fatalf("got %d fetches, requeue after %v", fetches, result.RequeueAfter)
+	}
+
+	// A reconcile triggered by a route or gateway does not fetch again
+	result, err = r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 1 || result.RequeueAfter <= 0 || result.RequeueAfter > time.Hour {
+		t.Errorf("got %d fetches, requeue after %v", fetches, result.RequeueAfter)
+	}
+}
+
+func TestAddressListReconcileTooManyEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := range securitypoliciesv1alpha1.AddressListSourceMaxEntries + 1 {
+			_, _ = fmt.Fprintf(w, "10.%d.%d.0/24\n", i/256, i%256)
+		}
+	}))
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayv1.Install(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := securitypoliciesv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	addressList := &securitypoliciesv1alpha1.AddressList{
+		ObjectMeta: metav1.ObjectMeta{Name: "azure", Generation: 1},
+		Spec: securitypoliciesv1alpha1.AddressListSpec{
+			Source: &securitypoliciesv1alpha1.AddressListSource{
+				HTTP: &securitypoliciesv1alpha1.HTTPAddressListSource{URL: server.URL},
+			},
+		},
+	}
+	r := &AddressListReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(addressList).
+			WithStatusSubresource(addressList).Build(),
+		HTTPClient: server.Client(),
+	}
+	ctx := context.Background()
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "azure"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var latest securitypoliciesv1alpha1.AddressList
+	if err := r.Get(ctx, types.NamespacedName{Name: "azure"}, &latest); err != nil {
+		t.Fatal(err)
+	}
+	condition := meta.FindStatusCondition(latest.Status.Conditions, securitypoliciesv1alpha1.AddressListConditionSourceSynced)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "TooManyEntries" {
+		t.Errorf("expected SourceSynced=False with reason TooManyEntries, got %+v", condition)
+	}
+	if latest.Status.Source == nil || len(latest.Status.Source.Entries) != 0 {
+		t.Errorf("expected no entries in the status")
+	}
+}
+
+func TestUntilNextFetch(t *testing.T) {
+	now := time.Now()
+	lastFetch := metav1.NewTime(now.Add(-20 * time.Minute))
+
+	if got := untilNextFetch(nil, time.Hour, now); got != 0 {
+		t.Errorf("expected a source that was never fetched to be due, got %v", got)
+	}
+	sourceStatus := &securitypoliciesv1alpha1.AddressListSourceStatus{LastFetchTime: &lastFetch}
+	if got := untilNextFetch(sourceStatus, time.Hour, now); got != 40*time.Minute {
+		t.Errorf("got %v, want 40m", got)
+	}
+	if got := untilNextFetch(sourceStatus, 10*time.Minute, now); got != 0 {
+		t.Errorf("expected an overdue source to be due, got %v", got)
+	}
+}
+
+func TestAddressListConsumerHandler(t *testing.T) {
+	route := func(lists string) *gatewayv1.HTTPRoute {
+		return &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "api",
+			Annotations: map[string]string{AnnotationSecurityPolicyLists: lists},
+		}}
+	}
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	// Both the dropped and the added AddressList are reconciled
+	addressListConsumerHandler.Update(context.Background(), event.UpdateEvent{
+		ObjectOld: route("addresslist:partners,addresslist:office"),
+		ObjectNew: route("addresslist:office,addresslist:cdn"),
+	}, queue)
+
+	var names []string
+	for queue.Len() > 0 {
+		request, _ := queue.Get()
+		names = append(names, request.Name)
+		queue.Done(request)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"cdn", "office", "partners"}) {
+		t.Errorf("got requests %v, want [cdn office partners]", names)
+	}
+}
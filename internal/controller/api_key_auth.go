@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"golang.org/x/net/http/httpguts"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// getAPIKeyAuth returns the API key authentication of the SecurityPolicy described by
+// the api-key- annotations, or nil if no Secrets are referenced. Each Secret must exist
+// in the namespace of the route or gateway and map client IDs to API keys. Client IDs
+// and API keys must be unique across all Secrets.
+func getAPIKeyAuth(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, annotations map[string]string) (*envoyv1.APIKeyAuth, error) {
+	secretNames := annotationEntries(annotations, AnnotationSecurityPolicyAPIKeySecrets)
+	if len(secretNames) == 0 {
+		return nil, nil
+	}
+
+	apiKeyAuth := &envoyv1.APIKeyAuth{}
+	clientIDs := map[string]string{}
+	keys := map[string]string{}
+	for _, secretName := range secretNames {
+		var secret corev1.Secret
+		if err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: gatewayApiResource.Namespace}, &secret); err != nil {
+			return nil, fmt.Errorf("unable to get API key Secret %s/%s: %w", gatewayApiResource.Namespace, secretName, err)
+		}
+		if len(secret.Data) == 0 {
+			return nil, fmt.Errorf("API key Secret %s/%s has no client IDs", secret.Namespace, secret.Name)
+		}
+
+		for clientID, key := range secret.Data {
+			if len(key) == 0 {
+				return nil, fmt.Errorf("API key Secret %s/%s has an empty key for client ID %q", secret.Namespace, secret.Name, clientID)
+			}
+			if other, exists := clientIDs[clientID]; exists {
+				return nil, fmt.Errorf("client ID %q is in both API key Secrets %s and %s", clientID, other, secret.Name)
+			}
+			clientIDs[clientID] = secret.Name
+			if other, exists := keys[string(key)]; exists {
+				return nil, fmt.Errorf("client IDs %q and %q share the same API key", other, clientID)
+			}
+			keys[string(key)] = clientID
+		}
+
+		apiKeyAuth.CredentialRefs = append(apiKeyAuth.CredentialRefs, gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(secretName)})
+	}
+
+	extractFrom := &envoyv1.ExtractFrom{
+		Headers: annotationEntries(annotations, AnnotationSecurityPolicyAPIKeyHeaders),
+		Params:  annotationEntries(annotations, AnnotationSecurityPolicyAPIKeyParams),
+	}
+	if len(extractFrom.Headers) == 0 && len(extractFrom.Params) == 0 {
+		return nil, fmt.Errorf("%s or %s is required to configure API key authentication", AnnotationSecurityPolicyAPIKeyHeaders, AnnotationSecurityPolicyAPIKeyParams)
+	}
+	for _, header := range extractFrom.Headers {
+		if !httpguts.ValidHeaderFieldName(header) {
+			return nil, fmt.Errorf("%s not valid: %q is not a valid header name", AnnotationSecurityPolicyAPIKeyHeaders, header)
+		}
+	}
+	apiKeyAuth.ExtractFrom = []*envoyv1.ExtractFrom{extractFrom}
+
+	if header := strings.TrimSpace(annotations[AnnotationSecurityPolicyAPIKeyForwardClientIDHeader]); header != "" {
+		if !httpguts.ValidHeaderFieldName(header) {
+			return nil, fmt.Errorf("%s not valid: %q is not a valid header name", AnnotationSecurityPolicyAPIKeyForwardClientIDHeader, header)
+		}
+		apiKeyAuth.ForwardClientIDHeader = &header
+	}
+
+	return apiKeyAuth, nil
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetAPIKeyAuth(t *testing.T) {
+	ctx := context.Background()
+	route := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "api"}
+	secret := func(name string, data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}, Data: map[string][]byte{}}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+	r := fake.NewClientBuilder().WithObjects(
+		secret("partner-a", map[string]string{"partner-a": "key-a"}),
+		secret("partner-b", map[string]string{"partner-b": "key-b", "partner-b-batch": "key-b2"}),
+		secret("partner-c", map[string]string{"partner-c": "key-a"}),
+		secret("empty", nil),
+	).Build()
+
+	apiKeyAuth, err := getAPIKeyAuth(ctx, r, route, map[string]string{
+		AnnotationSecurityPolicyAPIKeySecrets:               "partner-a,partner-b",
+		AnnotationSecurityPolicyAPIKeyHeaders:               "x-api-key",
+		AnnotationSecurityPolicyAPIKeyForwardClientIDHeader: "x-client-id",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apiKeyAuth.CredentialRefs) != 2 || apiKeyAuth.ExtractFrom[0].Headers[0] != "x-api-key" || *apiKeyAuth.ForwardClientIDHeader != "x-client-id" {
+		t.Errorf("got %+v", apiKeyAuth)
+	}
+
+	for _, annotations := range []map[string]string{
+		{AnnotationSecurityPolicyAPIKeySecrets: "partner-a"},
+		{AnnotationSecurityPolicyAPIKeySecrets: "missing", AnnotationSecurityPolicyAPIKeyHeaders: "x-api-key"},
+		{AnnotationSecurityPolicyAPIKeySecrets: "empty", AnnotationSecurityPolicyAPIKeyHeaders: "x-api-key"},
+		{AnnotationSecurityPolicyAPIKeySecrets: "partner-a,partner-c", AnnotationSecurityPolicyAPIKeyParams: "api_key"},
+		{AnnotationSecurityPolicyAPIKeySecrets: "partner-a,partner-a", AnnotationSecurityPolicyAPIKeyParams: "api_key"},
+	} {
+		if _, err := getAPIKeyAuth(ctx, r, route, annotations); err == nil {
+			t.Errorf("expected error for %v", annotations)
+		}
+	}
+}
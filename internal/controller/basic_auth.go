@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// getBasicAuth returns the basic authentication of the SecurityPolicy described by the
+// basic-auth- annotations, or nil if no Secret is referenced. The Secret must exist
+// in the namespace of the route or gateway and hold htpasswd users in the .htpasswd key.
+func getBasicAuth(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, annotations map[string]string) (*envoyv1.BasicAuth, error) {
+	secretName := strings.TrimSpace(annotations[AnnotationSecurityPolicyBasicAuthSecret])
+	if secretName == "" {
+		return nil, nil
+	}
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: gatewayApiResource.Namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("unable to get basic auth Secret %s/%s: %w", gatewayApiResource.Namespace, secretName, err)
+	}
+
+	htpasswd, ok := secret.Data[envoyv1.BasicAuthUsersSecretKey]
+	if !ok {
+		return nil, fmt.Errorf("basic auth Secret %s/%s has no %s key", secret.Namespace, secret.Name, envoyv1.BasicAuthUsersSecretKey)
+	}
+	if err := validateHtpasswd(htpasswd); err != nil {
+		return nil, fmt.Errorf("invalid %s in basic auth Secret %s/%s: %w", envoyv1.BasicAuthUsersSecretKey, secret.Namespace, secret.Name, err)
+	}
+
+	basicAuth := &envoyv1.BasicAuth{
+		Users: gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(secretName)},
+	}
+	if header := strings.TrimSpace(annotations[AnnotationSecurityPolicyBasicAuthForwardUsernameHeader]); header != "" {
+		basicAuth.ForwardUsernameHeader = &header
+	}
+
+	return basicAuth, nil
+}
+
+// validateHtpasswd checks that every line holds a unique user with a SHA hashed
+// password, "user:{SHA}hash", the only format supported by Envoy.
+func validateHtpasswd(htpasswd []byte) error {
+	users := map[string]struct{}{}
+	for i, line := range bytes.Split(htpasswd, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		user, hash, found := strings.Cut(string(line), ":")
+		if !found || user == "" {
+			return fmt.Errorf("line %d: expected user:{SHA}hash", i+1)
+		}
+		if _, exists := users[user]; exists {
+			return fmt.Errorf("line %d: duplicate user %q", i+1, user)
+		}
+		users[user] = struct{}{}
+
+		encoded, isSHA := strings.CutPrefix(hash, "{SHA}")
+		if !isSHA {
+			return fmt.Errorf("line %d: password of user %q is not SHA hashed, the only format supported", i+1, user)
+		}
+		if digest, err := base64.StdEncoding.DecodeString(encoded); err != nil || len(digest) != sha1.Size {
+			return fmt.Errorf("line %d: invalid SHA hash for user %q", i+1, user)
+		}
+	}
+
+	if len(users) == 0 {
+		return fmt.Errorf("no users")
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package controller
+
+import "testing"
+
+func TestValidateHtpasswd(t *testing.T) {
+	// htpasswd -nbs alice secret
+	if err := validateHtpasswd([]byte("alice:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=\n\nbob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for _, htpasswd := range []string{
+		"",
+		"alice",
+		"alice:$apr1$ZxpVd1uF$1nC6ZKhrdlxJ0Qxy4j.pV0",
+		"alice:{SHA}not-base64",
+		"alice:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=\nalice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=",
+	} {
+		if err := validateHtpasswd([]byte(htpasswd)); err == nil {
+			t.Errorf("expected error for %q", htpasswd)
+		}
+	}
+}
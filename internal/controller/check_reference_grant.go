@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// checkReferenceGrant reports whether a ReferenceGrant in the namespace of the list
+// permits the given Gateway API resource to reference it.
+func checkReferenceGrant(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, reference listReference) (bool, error) {
+
+	// Get all ReferenceGrants in the namespace of the referenced list
+	referenceGrantList := &gatewayv1beta1.ReferenceGrantList{}
+	if err := r.List(ctx, referenceGrantList, client.InNamespace(reference.Namespace)); err != nil {
+		return false, err
+	}
+
+	for _, referenceGrant := range referenceGrantList.Items {
+		fromAllowed := false
+		for _, from := range referenceGrant.Spec.From {
+			if string(from.Group) == GatewayAPIGroup &&
+				string(from.Kind) == gatewayApiResource.Kind &&
+				string(from.Namespace) == gatewayApiResource.Namespace {
+				fromAllowed = true
+				break
+			}
+		}
+		if !fromAllowed {
+			continue
+		}
+
+		for _, to := range referenceGrant.Spec.To {
+			if string(to.Group) == reference.group() &&
+				string(to.Kind) == reference.Kind &&
+				(to.Name == nil || string(*to.Name) == reference.Name) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// checkReferencePermitted returns an error if resolving the reference from the given
+// Gateway API resource crosses a namespace boundary without a ReferenceGrant.
+func checkReferencePermitted(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, reference listReference) error {
+
+	// Cross-namespace references must be permitted by a ReferenceGrant in the referenced namespace
+	if !reference.requiresReferenceGrant(gatewayApiResource) {
+		return nil
+	}
+
+	allowed, err := checkReferenceGrant(ctx, r, gatewayApiResource, reference)
+	if err != nil {
+		return fmt.Errorf("unable to check ReferenceGrants for %s %q: %w", reference.Kind, reference, err)
+	}
+	if !allowed {
+		return &referenceNotPermittedError{gatewayApiResource: gatewayApiResource, reference: reference}
+	}
+
+	return nil
+}
+
+// referenceNotPermittedError is returned for a cross-namespace reference that no
+// ReferenceGrant permits.
+type referenceNotPermittedError struct {
+	gatewayApiResource gatewayApiResource
+	reference          listReference
+}
+
+func (e *referenceNotPermittedError) Error() string {
+	return fmt.Sprintf("reference from %s %s/%s to %s %q is not permitted by any ReferenceGrant in namespace %q",
+		e.gatewayApiResource.Kind, e.gatewayApiResource.Namespace, e.gatewayApiResource.Name, e.reference.Kind, e.reference, e.reference.Namespace)
+}
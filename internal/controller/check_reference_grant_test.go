@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// newReferenceGrantTestClient returns a fake client holding objects and a ReferenceGrant
+// in the shared namespace that lets HTTPRoutes in default reference the partners ConfigMap.
+func newReferenceGrantTestClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayv1.Install(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayv1beta1.Install(scheme); err != nil {
+		t.Fatal(err)
+	}
+	partners := gatewayv1.ObjectName("partners")
+	referenceGrant := &gatewayv1beta1.ReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "routes"},
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{{Group: GatewayAPIGroup, Kind: "HTTPRoute", Namespace: "default"}},
+			To:   []gatewayv1beta1.ReferenceGrantTo{{Group: "", Kind: ConfigMapKind, Name: &partners}},
+		},
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, referenceGrant)...).Build()
+}
+
+func TestCheckReferenceGrant(t *testing.T) {
+	ctx := context.Background()
+	r := newReferenceGrantTestClient(t)
+	route := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "api"}
+	partners := listReference{Kind: ConfigMapKind, Namespace: "shared", Name: "partners"}
+
+	for _, test := range []struct {
+		resource  gatewayApiResource
+		reference listReference
+		want      bool
+	}{
+		{route, partners, true},
+		{gatewayApiResource{Kind: "GRPCRoute", Namespace: "default", Name: "api"}, partners, false},
+		{gatewayApiResource{Kind: "HTTPRoute", Namespace: "other", Name: "api"}, partners, false},
+		{route, listReference{Kind: ConfigMapKind, Namespace: "shared", Name: "office"}, false},
+		{route, listReference{Kind: NetworkPolicyKind, Namespace: "shared", Name: "partners"}, false},
+	} {
+		got, err := checkReferenceGrant(ctx, r, test.resource, test.reference)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("checkReferenceGrant(%v, %v) = %v, want %v", test.resource, test.reference, got, test.want)
+		}
+	}
+
+	// A denied reference is reported as an event on the route and not retried
+	err := checkReferencePermitted(ctx, r, route, listReference{Kind: ConfigMapKind, Namespace: "shared", Name: "office"})
+	var notPermitted *referenceNotPermittedError
+	if !errors.As(err, &notPermitted) {
+		t.Fatalf("expected a referenceNotPermittedError, got %v", err)
+	}
+	recorder := events.NewFakeRecorder(1)
+	if err := handleReconcileErrors(recorder, &gatewayv1.HTTPRoute{}, fmt.Errorf("SecurityPolicy: %w", err)); err != nil {
+		t.Errorf("expected the denied reference not to be retried, got %v", err)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, EventReasonReferenceNotPermitted) {
+			t.Errorf("got event %q", event)
+		}
+	default:
+		t.Error("expected an event for the denied reference")
+	}
+}
+
+func TestReferenceGrantConsumers(t *testing.T) {
+	route := func(namespace string, name string, annotations map[string]string) *gatewayv1.HTTPRoute {
+		return &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations}}
+	}
+	r := newReferenceGrantTestClient(t,
+		route("default", "lists", map[string]string{AnnotationSecurityPolicyLists: "configmap:shared/partners"}),
+		route("default", "service", map[string]string{AnnotationSecurityPolicyAddresses: "@service:shared/ingress"}),
+		route("default", "platform", map[string]string{AnnotationSecurityPolicyLists: "office"}),
+		route("shared", "local", map[string]string{AnnotationSecurityPolicyLists: "configmap:local:partners"}),
+	)
+
+	consumers, err := referenceGrantConsumers(context.Background(), r, "shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, consumer := range consumers {
+		names = append(names, consumer.Name)
+	}
+	if len(names) != 2 || names[0] != "lists" || names[1] != "service" {
+		t.Errorf("got consumers %v, want [lists service]", names)
+	}
+}
@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"golang.org/x/net/http/httpguts"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// clientTrafficPolicyAnnotations are the annotations that configure the
+// ClientTrafficPolicy of a gateway.
+var clientTrafficPolicyAnnotations = []string{
+	AnnotationSecurityPolicyClientIPTrustedHops,
+	AnnotationSecurityPolicyClientIPTrustedLists,
+	AnnotationSecurityPolicyClientIPHeader,
+	AnnotationSecurityPolicyProxyProtocol,
+}
+
+// hasClientTrafficPolicyAnnotations reports whether any annotation configuring the
+// ClientTrafficPolicy is set.
+func hasClientTrafficPolicyAnnotations(annotations map[string]string) bool {
+	return slices.ContainsFunc(clientTrafficPolicyAnnotations, func(annotation string) bool {
+		return annotations[annotation] != ""
+	})
+}
+
+// clientTrafficPolicyAnnotationsChanged reports whether any annotation configuring
+// the ClientTrafficPolicy differs between oldAnnotations and newAnnotations.
+func clientTrafficPolicyAnnotationsChanged(oldAnnotations map[string]string, newAnnotations map[string]string) bool {
+	return slices.ContainsFunc(clientTrafficPolicyAnnotations, func(annotation string) bool {
+		return oldAnnotations[annotation] != newAnnotations[annotation]
+	})
+}
+
+// getClientIPDetection returns the client IP detection described by the client-ip-
+// annotations, or nil if none is set. The X-Forwarded-For header is trusted either
+// for a number of hops or from the CIDRs of the trusted lists, a custom header
+// replaces the X-Forwarded-For header.
+func getClientIPDetection(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, annotations map[string]string) (*envoyv1.ClientIPDetectionSettings, error) {
+	hops, hasHops := annotations[AnnotationSecurityPolicyClientIPTrustedHops]
+	lists := annotationEntries(annotations, AnnotationSecurityPolicyClientIPTrustedLists)
+	header := annotations[AnnotationSecurityPolicyClientIPHeader]
+
+	if hasHops && len(lists) > 0 {
+		return nil, fmt.Errorf("only one of %s and %s can be set", AnnotationSecurityPolicyClientIPTrustedHops, AnnotationSecurityPolicyClientIPTrustedLists)
+	}
+	if header != "" && (hasHops || len(lists) > 0) {
+		return nil, fmt.Errorf("%s cannot be combined with %s or %s", AnnotationSecurityPolicyClientIPHeader, AnnotationSecurityPolicyClientIPTrustedHops, AnnotationSecurityPolicyClientIPTrustedLists)
+	}
+
+	switch {
+	case hasHops:
+		numTrustedHops, err := strconv.ParseUint(hops, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s not valid: %q. Expected a number of hops", AnnotationSecurityPolicyClientIPTrustedHops, hops)
+		}
+		trustedHops := uint32(numTrustedHops)
+		return &envoyv1.ClientIPDetectionSettings{
+			XForwardedFor: &envoyv1.XForwardedForSettings{NumTrustedHops: &trustedHops},
+		}, nil
+
+	case len(lists) > 0:
+		sources, err := getAddresses(ctx, r, gatewayApiResource, "", lists, nil, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		var cidrs []string
+		for _, source := range sources {
+			cidrs = append(cidrs, source.CIDRs...)
+		}
+		trusted, err := newAddressSource(AnnotationSecurityPolicyClientIPTrustedLists, cidrs)
+		if err != nil {
+			return nil, err
+		}
+		if len(trusted.CIDRs) == 0 {
+			return nil, fmt.Errorf("no CIDRs found in %s", AnnotationSecurityPolicyClientIPTrustedLists)
+		}
+		settings := &envoyv1.XForwardedForSettings{}
+		for _, cidr := range trusted.CIDRs {
+			settings.TrustedCIDRs = append(settings.TrustedCIDRs, envoyv1.CIDR(cidr))
+		}
+		return &envoyv1.ClientIPDetectionSettings{XForwardedFor: settings}, nil
+
+	case header != "":
+		if !httpguts.ValidHeaderFieldName(header) {
+			return nil, fmt.Errorf("%s not valid: %q. Expected a header name", AnnotationSecurityPolicyClientIPHeader, header)
+		}
+		return &envoyv1.ClientIPDetectionSettings{
+			CustomHeader: &envoyv1.CustomHeaderExtensionSettings{Name: header},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// parseProxyProtocolAnnotation returns the PROXY protocol settings of the
+// proxy-protocol annotation, or nil if it is not set or false. With optional,
+// connections without the PROXY protocol header are accepted as well.
+func parseProxyProtocolAnnotation(annotations map[string]string) (*envoyv1.ProxyProtocolSettings, error) {
+	value, ok := annotations[AnnotationSecurityPolicyProxyProtocol]
+	if !ok {
+		return nil, nil
+	}
+	switch value {
+	case "true":
+		return &envoyv1.ProxyProtocolSettings{}, nil
+	case ProxyProtocolOptional:
+		optional := true
+		return &envoyv1.ProxyProtocolSettings{Optional: &optional}, nil
+	case "false":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%s not valid: %q. Valid values: true || false || %s", AnnotationSecurityPolicyProxyProtocol, value, ProxyProtocolOptional)
+	}
+}
+
+// reconcileClientTrafficPolicy creates or updates the ClientTrafficPolicy of a gateway
+// from its annotations, or deletes it if none is set. The ClientTrafficPolicy has the
+// same name as the SecurityPolicy of the gateway and is marked with the managed-by
+// annotation. A ClientTrafficPolicy that is not marked, or another ClientTrafficPolicy
+// targeting the gateway, is a conflict.
+func reconcileClientTrafficPolicy(ctx context.Context, r client.Client, gatewayApiResource gatewayApiResource, annotations map[string]string) error {
+	if !hasClientTrafficPolicyAnnotations(annotations) {
+		return deleteClientTrafficPolicy(ctx, r, gatewayApiResource)
+	}
+
+	clientIPDetection, err := getClientIPDetection(ctx, r, gatewayApiResource, annotations)
+	if err != nil {
+		return err
+	}
+	proxyProtocol, err := parseProxyProtocolAnnotation(annotations)
+	if err != nil {
+		return err
+	}
+
+	targetRefs := []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+		{
+			LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+				Group: gatewayv1.Group(GatewayAPIGroup),
+				Kind:  gatewayv1.Kind(gatewayApiResource.Kind),
+				Name:  gatewayv1.ObjectName(gatewayApiResource.Name),
+			},
+		},
+	}
+
+	// Envoy Gateway applies only the oldest ClientTrafficPolicy targeting a gateway
+	clientTrafficPolicyList := &envoyv1.ClientTrafficPolicyList{}
+	if err := r.List(ctx, clientTrafficPolicyList, client.InNamespace(gatewayApiResource.Namespace)); err != nil {
+		return err
+	}
+	for _, other := range clientTrafficPolicyList.Items {
+		if other.Name == gatewayApiResource.securityPolicyName() {
+			continue
+		}
+		if slices.ContainsFunc(other.Spec.TargetRefs, gatewayApiResource.targetedBy) {
+			return fmt.Errorf("ClientTrafficPolicy %s/%s already targets %s %s", other.Namespace, other.Name, gatewayApiResource.Kind, gatewayApiResource.Name)
+		}
+	}
+
+	var clientTrafficPolicy envoyv1.ClientTrafficPolicy
+	err = r.Get(ctx, client.ObjectKey{Name: gatewayApiResource.securityPolicyName(), Namespace: gatewayApiResource.Namespace}, &clientTrafficPolicy)
+	if apierrors.IsNotFound(err) {
+		clientTrafficPolicy = envoyv1.ClientTrafficPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        gatewayApiResource.securityPolicyName(),
+				Namespace:   gatewayApiResource.Namespace,
+				Annotations: map[string]string{AnnotationSecurityPolicyManagedBy: SecurityPolicyOwner},
+			},
+			Spec: envoyv1.ClientTrafficPolicySpec{
+				PolicyTargetReferences: envoyv1.PolicyTargetReferences{TargetRefs: targetRefs},
+				ClientIPDetection:      clientIPDetection,
+				ProxyProtocol:          proxyProtocol,
+			},
+		}
+		if err := r.Create(ctx, &clientTrafficPolicy); err != nil {
+			return fmt.Errorf("failed to create ClientTrafficPolicy: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !managedByOperator(&clientTrafficPolicy) {
+		return fmt.Errorf("ClientTrafficPolicy %s/%s already exists and is not managed by %s", clientTrafficPolicy.Namespace, clientTrafficPolicy.Name, SecurityPolicyOwner)
+	}
+
+	clientTrafficPolicy.Spec.TargetRefs = targetRefs
+	clientTrafficPolicy.Spec.ClientIPDetection = clientIPDetection
+	clientTrafficPolicy.Spec.ProxyProtocol = proxyProtocol
+	if err := r.Update(ctx, &clientTrafficPolicy); err != nil {
+		return fmt.Errorf("failed to update ClientTrafficPolicy: %w", err)
+	}
+
+	return nil
+}
+
+// deleteClientTrafficPolicy deletes the ClientTrafficPolicy of a gateway if it exists
+// and is managed by the operator.
+func deleteClientTrafficPolicy(ctx context.Context, r client.Client, gatewayApiResource gatewayApiResource) error {
+	clientTrafficPolicy := envoyv1.ClientTrafficPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gatewayApiResource.securityPolicyName(),
+			Namespace: gatewayApiResource.Namespace,
+		},
+	}
+	return deleteManagedObject(ctx, r, &clientTrafficPolicy)
+}
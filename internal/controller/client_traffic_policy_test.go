@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestGetClientIPDetection(t *testing.T) {
+	ctx := context.Background()
+	gateway := gatewayApiResource{Kind: "Gateway", Namespace: "default", Name: "public"}
+	r := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cdn"},
+		Data:       map[string]string{"ranges": "192.0.2.0/25\n192.0.2.128/25\n198.51.100.0/24"},
+	}).Build()
+
+	clientIPDetection, err := getClientIPDetection(ctx, r, gateway, map[string]string{
+		AnnotationSecurityPolicyClientIPTrustedLists: "configmap:local:cdn",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := clientIPDetection.XForwardedFor.TrustedCIDRs; len(got) != 2 || got[0] != "192.0.2.0/24" {
+		t.Errorf("got trusted CIDRs %v", got)
+	}
+
+	clientIPDetection, err = getClientIPDetection(ctx, r, gateway, map[string]string{
+		AnnotationSecurityPolicyClientIPTrustedHops: "2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *clientIPDetection.XForwardedFor.NumTrustedHops != 2 {
+		t.Errorf("got %+v", clientIPDetection.XForwardedFor)
+	}
+
+	for _, annotations := range []map[string]string{
+		{AnnotationSecurityPolicyClientIPTrustedHops: "-1"},
+		{AnnotationSecurityPolicyClientIPTrustedHops: "1", AnnotationSecurityPolicyClientIPTrustedLists: "configmap:local:cdn"},
+		{AnnotationSecurityPolicyClientIPHeader: "x-real-ip", AnnotationSecurityPolicyClientIPTrustedHops: "1"},
+		{AnnotationSecurityPolicyClientIPHeader: "x real ip"},
+		{AnnotationSecurityPolicyClientIPTrustedLists: "configmap:local:missing"},
+	} {
+		if _, err := getClientIPDetection(ctx, r, gateway, annotations); err == nil {
+			t.Errorf("expected error for %v", annotations)
+		}
+	}
+}
+
+func TestReconcileClientTrafficPolicy(t *testing.T) {
+	ctx := context.Background()
+	gateway := gatewayApiResource{Kind: "Gateway", Namespace: "default", Name: "public"}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := envoyv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	r := fake.NewClientBuilder().WithScheme(scheme).Build()
+	key := client.ObjectKey{Namespace: "default", Name: gateway.securityPolicyName()}
+
+	if err := reconcileClientTrafficPolicy(ctx, r, gateway, map[string]string{
+		AnnotationSecurityPolicyClientIPHeader: "x-real-ip",
+		AnnotationSecurityPolicyProxyProtocol:  "optional",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var clientTrafficPolicy envoyv1.ClientTrafficPolicy
+	if err := r.Get(ctx, key, &clientTrafficPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if clientTrafficPolicy.Spec.TargetRefs[0].Name != "public" || clientTrafficPolicy.Spec.ClientIPDetection.CustomHeader.Name != "x-real-ip" || !*clientTrafficPolicy.Spec.ProxyProtocol.Optional {
+		t.Errorf("got %+v", clientTrafficPolicy.Spec)
+	}
+
+	if err := reconcileClientTrafficPolicy(ctx, r, gateway, map[string]string{
+		AnnotationSecurityPolicyProxyProtocol: "true",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &clientTrafficPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if clientTrafficPolicy.Spec.ClientIPDetection != nil || clientTrafficPolicy.Spec.ProxyProtocol.Optional != nil {
+		t.Errorf("got %+v", clientTrafficPolicy.Spec)
+	}
+
+	if !managedByOperator(&clientTrafficPolicy) {
+		t.Errorf("expected ClientTrafficPolicy to be marked as managed, got %v", clientTrafficPolicy.Annotations)
+	}
+
+	// Another ClientTrafficPolicy targeting the gateway is a conflict
+	conflicting := &envoyv1.ClientTrafficPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "public-clients"},
+		Spec: envoyv1.ClientTrafficPolicySpec{
+			PolicyTargetReferences: envoyv1.PolicyTargetReferences{
+				TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+					{LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{Group: GatewayAPIGroup, Kind: "Gateway", Name: "public"}},
+				},
+			},
+		},
+	}
+	if err := r.Create(ctx, conflicting); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileClientTrafficPolicy(ctx, r, gateway, map[string]string{AnnotationSecurityPolicyProxyProtocol: "true"}); err == nil {
+		t.Error("expected error for another ClientTrafficPolicy targeting the gateway")
+	}
+	if err := r.Delete(ctx, conflicting); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reconcileClientTrafficPolicy(ctx, r, gateway, map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &clientTrafficPolicy); !apierrors.IsNotFound(err) {
+		t.Errorf("expected ClientTrafficPolicy to be deleted, got %v", err)
+	}
+
+	// A ClientTrafficPolicy that is not managed by the operator is neither updated nor deleted
+	unmanaged := &envoyv1.ClientTrafficPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: gateway.securityPolicyName()}}
+	if err := r.Create(ctx, unmanaged); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileClientTrafficPolicy(ctx, r, gateway, map[string]string{AnnotationSecurityPolicyProxyProtocol: "true"}); err == nil {
+		t.Error("expected error for a ClientTrafficPolicy not managed by the operator")
+	}
+	if err := reconcileClientTrafficPolicy(ctx, r, gateway, map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &clientTrafficPolicy); err != nil {
+		t.Errorf("expected ClientTrafficPolicy to be kept, got %v", err)
+	}
+}
@@ -2,25 +2,70 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"strings"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 type Client interface {
 	Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error
+	List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error
 	Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error
 	Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error
 }
 
+// gatewayApiResource identifies a route or gateway. SectionName is set for the
+// SecurityPolicy of a single listener of a gateway.
 type gatewayApiResource struct {
-	Name      string
-	Namespace string
-	Kind      string
+	Name        string
+	Namespace   string
+	Kind        string
+	SectionName string
 }
 
 // securityPolicyName returns the kind-prefixed name used for the SecurityPolicy
 // resource, keeping it distinct from the targetRef name (the real object name).
+// The SecurityPolicy of a listener is prefixed with "listener-", which no kind prefix
+// starts with, and suffixed with a hash of the object and listener name, since
+// both may contain dashes.
 func (g gatewayApiResource) securityPolicyName() string {
+	if g.SectionName != "" {
+		hash := sha256.Sum256([]byte(g.Kind + "/" + g.Name + "/" + g.SectionName))
+		return "listener-" + strings.ToLower(g.Kind) + "-" + g.Name + "-" + hex.EncodeToString(hash[:4])
+	}
 	return strings.ToLower(g.Kind) + "-" + g.Name
 }
+
+// targetedBy reports whether targetRef targets the resource, including the listener
+// for a SectionName.
+func (g gatewayApiResource) targetedBy(targetRef gatewayv1.LocalPolicyTargetReferenceWithSectionName) bool {
+	sectionName := ""
+	if targetRef.SectionName != nil {
+		sectionName = string(*targetRef.SectionName)
+	}
+	return string(targetRef.Name) == g.Name &&
+		string(targetRef.Kind) == g.Kind &&
+		sectionName == g.SectionName
+}
+
+// managedByOperator reports whether object was created by the operator, which marks
+// the policies it creates with the managed-by annotation.
+func managedByOperator(object metav1.Object) bool {
+	return object.GetAnnotations()[AnnotationSecurityPolicyManagedBy] == SecurityPolicyOwner
+}
+
+// deleteManagedObject deletes object if it exists and is managed by the operator.
+// Objects with the same name that were created by someone else are left alone.
+func deleteManagedObject(ctx context.Context, r client.Client, object client.Object) error {
+	if err := r.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !managedByOperator(object) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Delete(ctx, object))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ConfigMapReconciler reconciles a ConfigMap object
+type ConfigMapReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+// Reconcile triggers reconciliation of all routes and gateways that reference
+// the ConfigMap in their lists annotation. Deleted ConfigMaps are reconciled as
+// well, since the lists can no longer be read.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
+func (r *ConfigMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	log.Info("Reconciling ConfigMap", "ConfigMap.Namespace", req.Namespace, "ConfigMap.Name", req.Name)
+
+	// Find all HttpRoutes, GRPCRoutes and Gateways that reference the ConfigMap
+	consumers, err := configMapConsumers(ctx, r.Client, req.Namespace, req.Name)
+	if err != nil {
+		log.Error(err, "Failed to list consumers of ConfigMap")
+		return ctrl.Result{}, err
+	}
+
+	// Update each consumer to trigger reconciliation
+	for _, consumer := range consumers {
+		if err := notifyController(ctx, r.Client, consumer.Object); err != nil {
+			log.Error(err, "Failed to notify "+consumer.Kind, consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+			return ctrl.Result{}, err
+		}
+		log.Info("Patched "+consumer.Kind+" due to ConfigMap change", consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// referencedConfigMapPredicate passes events of ConfigMaps referenced by a route or
+// gateway. ConfigMaps created before started are skipped, since routes and gateways
+// are reconciled on startup anyway. Updates only pass when the data changed.
+func referencedConfigMapPredicate(r Client, started time.Time) predicate.Funcs {
+	referenced := func(object client.Object) bool {
+		consumers, err := configMapConsumers(context.Background(), r, object.GetNamespace(), object.GetName())
+		return err == nil && len(consumers) > 0
+	}
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldConfigMap, okOld := e.ObjectOld.(*corev1.ConfigMap)
+			newConfigMap, okNew := e.ObjectNew.(*corev1.ConfigMap)
+			if !okOld || !okNew {
+				return false
+			}
+			return !reflect.DeepEqual(oldConfigMap.Data, newConfigMap.Data) && referenced(newConfigMap)
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return !e.Object.GetCreationTimestamp().Time.Before(started) && referenced(e.Object)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return referenced(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ConfigMapReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.ConfigMap{}).
+		Named("configmap").
+		WithEventFilter(referencedConfigMapPredicate(r.Client, time.Now().Truncate(time.Second))).
+		Complete(r)
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestReferencedConfigMapPredicate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayv1.Install(scheme); err != nil {
+		t.Fatal(err)
+	}
+	r := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "api",
+			Annotations: map[string]string{AnnotationSecurityPolicyLists: "configmap:local:partners"},
+		},
+	}).Build()
+	started := time.Now().Truncate(time.Second)
+	referencedConfigMap := referencedConfigMapPredicate(r, started)
+
+	configMap := func(name string, created time.Time, data string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "default",
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Data: map[string]string{"cidrs": data},
+		}
+	}
+	before := started.Add(-time.Hour)
+
+	// ConfigMaps replayed on startup are skipped
+	if referencedConfigMap.Create(event.CreateEvent{Object: configMap("partners", before, "10.0.0.0/8")}) {
+		t.Error("expected ConfigMap created before the start to be skipped")
+	}
+	if !referencedConfigMap.Create(event.CreateEvent{Object: configMap("partners", started, "10.0.0.0/8")}) {
+		t.Error("expected new referenced ConfigMap to pass")
+	}
+	if referencedConfigMap.Create(event.CreateEvent{Object: configMap("other", started, "10.0.0.0/8")}) {
+		t.Error("expected unreferenced ConfigMap to be skipped")
+	}
+
+	if !referencedConfigMap.Update(event.UpdateEvent{ObjectOld: configMap("partners", before, "10.0.0.0/8"), ObjectNew: configMap("partners", before, "192.0.2.0/24")}) {
+		t.Error("expected changed referenced ConfigMap to pass")
+	}
+	if referencedConfigMap.Update(event.UpdateEvent{ObjectOld: configMap("partners", before, "10.0.0.0/8"), ObjectNew: configMap("partners", before, "10.0.0.0/8")}) {
+		t.Error("expected ConfigMap with unchanged data to be skipped")
+	}
+	if referencedConfigMap.Update(event.UpdateEvent{ObjectOld: configMap("other", before, "10.0.0.0/8"), ObjectNew: configMap("other", before, "192.0.2.0/24")}) {
+		t.Error("expected unreferenced ConfigMap to be skipped")
+	}
+
+	if !referencedConfigMap.Delete(event.DeleteEvent{Object: configMap("partners", before, "10.0.0.0/8")}) {
+		t.Error("expected deleted referenced ConfigMap to pass")
+	}
+	if referencedConfigMap.Delete(event.DeleteEvent{Object: configMap("other", before, "10.0.0.0/8")}) {
+		t.Error("expected deleted unreferenced ConfigMap to be skipped")
+	}
+}
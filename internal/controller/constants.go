@@ -1,15 +1,112 @@
 package controller
 
+import "time"
+
+const (
+	NetworkPoliciesNamespace                               = "network-policies"
+	AnnotationSecurityPolicyDefaultAction                  = "securitypolicies.vitistack.io/default-action"
+	AnnotationSecurityPolicyLists                          = "securitypolicies.vitistack.io/lists"
+	AnnotationSecurityPolicyAddresses                      = "securitypolicies.vitistack.io/addresses"
+	AnnotationSecurityPolicyExpression                     = "securitypolicies.vitistack.io/expression"
+	AnnotationSecurityPolicyAllowLists                     = "securitypolicies.vitistack.io/allow-lists"
+	AnnotationSecurityPolicyAllowAddresses                 = "securitypolicies.vitistack.io/allow-addresses"
+	AnnotationSecurityPolicyDenyLists                      = "securitypolicies.vitistack.io/deny-lists"
+	AnnotationSecurityPolicyDenyAddresses                  = "securitypolicies.vitistack.io/deny-addresses"
+	AnnotationSecurityPolicyRuleMode                       = "securitypolicies.vitistack.io/rule-mode"
+	AnnotationSecurityPolicyRules                          = "securitypolicies.vitistack.io/rules"
+	AnnotationSecurityPolicyMethods                        = "securitypolicies.vitistack.io/methods"
+	AnnotationSecurityPolicyAllowMethods                   = "securitypolicies.vitistack.io/allow-methods"
+	AnnotationSecurityPolicyDenyMethods                    = "securitypolicies.vitistack.io/deny-methods"
+	AnnotationSecurityPolicyHeaders                        = "securitypolicies.vitistack.io/headers"
+	AnnotationSecurityPolicyAllowHeaders                   = "securitypolicies.vitistack.io/allow-headers"
+	AnnotationSecurityPolicyDenyHeaders                    = "securitypolicies.vitistack.io/deny-headers"
+	AnnotationSecurityPolicyJWTIssuer                      = "securitypolicies.vitistack.io/jwt-issuer"
+	AnnotationSecurityPolicyJWTJWKSURI                     = "securitypolicies.vitistack.io/jwt-jwks-uri"
+	AnnotationSecurityPolicyJWTAudiences                   = "securitypolicies.vitistack.io/jwt-audiences"
+	AnnotationSecurityPolicyJWTOptional                    = "securitypolicies.vitistack.io/jwt-optional"
+	AnnotationSecurityPolicyJWTClaims                      = "securitypolicies.vitistack.io/jwt-claims"
+	AnnotationSecurityPolicyJWTScopes                      = "securitypolicies.vitistack.io/jwt-scopes"
+	AnnotationSecurityPolicyBasicAuthSecret                = "securitypolicies.vitistack.io/basic-auth-secret"
+	AnnotationSecurityPolicyBasicAuthForwardUsernameHeader = "securitypolicies.vitistack.io/basic-auth-forward-username-header"
+	AnnotationSecurityPolicyCORSAllowOrigins               = "securitypolicies.vitistack.io/cors-allow-origins"
+	AnnotationSecurityPolicyCORSAllowMethods               = "securitypolicies.vitistack.io/cors-allow-methods"
+	AnnotationSecurityPolicyCORSAllowHeaders               = "securitypolicies.vitistack.io/cors-allow-headers"
+	AnnotationSecurityPolicyCORSExposeHeaders              = "securitypolicies.vitistack.io/cors-expose-headers"
+	AnnotationSecurityPolicyCORSMaxAge                     = "securitypolicies.vitistack.io/cors-max-age"
+	AnnotationSecurityPolicyCORSAllowCredentials           = "securitypolicies.vitistack.io/cors-allow-credentials"
+	AnnotationSecurityPolicyExtAuthService                 = "securitypolicies.vitistack.io/ext-auth-service"
+	AnnotationSecurityPolicyExtAuthPort                    = "securitypolicies.vitistack.io/ext-auth-port"
+	AnnotationSecurityPolicyExtAuthProtocol                = "securitypolicies.vitistack.io/ext-auth-protocol"
+	AnnotationSecurityPolicyExtAuthPath                    = "securitypolicies.vitistack.io/ext-auth-path"
+	AnnotationSecurityPolicyExtAuthHeaders                 = "securitypolicies.vitistack.io/ext-auth-headers"
+	AnnotationSecurityPolicyExtAuthHeadersToBackend        = "securitypolicies.vitistack.io/ext-auth-headers-to-backend"
+	AnnotationSecurityPolicyExtAuthFailOpen                = "securitypolicies.vitistack.io/ext-auth-fail-open"
+	AnnotationSecurityPolicyOIDCIssuer                     = "securitypolicies.vitistack.io/oidc-issuer"
+	AnnotationSecurityPolicyOIDCClientID                   = "securitypolicies.vitistack.io/oidc-client-id"
+	AnnotationSecurityPolicyOIDCClientSecret               = "securitypolicies.vitistack.io/oidc-client-secret"
+	AnnotationSecurityPolicyOIDCScopes                     = "securitypolicies.vitistack.io/oidc-scopes"
+	AnnotationSecurityPolicyOIDCRedirectURL                = "securitypolicies.vitistack.io/oidc-redirect-url"
+	AnnotationSecurityPolicyAPIKeySecrets                  = "securitypolicies.vitistack.io/api-key-secrets"
+	AnnotationSecurityPolicyAPIKeyHeaders                  = "securitypolicies.vitistack.io/api-key-headers"
+	AnnotationSecurityPolicyAPIKeyParams                   = "securitypolicies.vitistack.io/api-key-params"
+	AnnotationSecurityPolicyAPIKeyForwardClientIDHeader    = "securitypolicies.vitistack.io/api-key-forward-client-id-header"
+	AnnotationSecurityPolicyClientIPTrustedHops            = "securitypolicies.vitistack.io/client-ip-trusted-hops"
+	AnnotationSecurityPolicyClientIPTrustedLists           = "securitypolicies.vitistack.io/client-ip-trusted-lists"
+	AnnotationSecurityPolicyClientIPHeader                 = "securitypolicies.vitistack.io/client-ip-header"
+	AnnotationSecurityPolicyProxyProtocol                  = "securitypolicies.vitistack.io/proxy-protocol"
+	AnnotationSecurityPolicyRateLimit                      = "securitypolicies.vitistack.io/rate-limit"
+	AnnotationSecurityPolicyRateLimitTiers                 = "securitypolicies.vitistack.io/rate-limit-tiers"
+	AnnotationSecurityPolicyRateLimitType                  = "securitypolicies.vitistack.io/rate-limit-type"
+	AnnotationSecurityPolicyListeners                      = "securitypolicies.vitistack.io/listeners"
+	AnnotationSecurityPolicyPrefix                         = "securitypolicies.vitistack.io/"
+	AnnotationSecurityPolicyLastUpdated                    = "securitypolicies.vitistack.io/last-updated"
+	AnnotationSecurityPolicyManagedBy                      = "securitypolicies.vitistack.io/managed-by"
+	AnnotationSecurityPolicyResolvedHosts                  = "securitypolicies.vitistack.io/resolved-hosts"
+	SecurityPolicyOwner                                    = "gatewayapi-securitypolicy-operator"
+	AnnotationSecurityPolicyGateway                        = "securitypolicies.vitistack.io/gateway"
+	DefaultAPIGatewayName                                  = "envoy-proxy"
+	FinalizerNetworkPolicy                                 = "networkpolicies.vitistack.io/finalizer"
+	FinalizerSecurityPolicy                                = "securitypolicies.vitistack.io/finalizer"
+	GatewayAPIGroup                                        = "gateway.networking.k8s.io"
+	NetworkPolicyGroup                                     = "networking.k8s.io"
+	NetworkPolicyKind                                      = "NetworkPolicy"
+	ListReferenceLocalPrefix                               = "local:"
+	ListReferenceAddressListPrefix                         = "addresslist:"
+	ListReferenceConfigMapPrefix                           = "configmap:"
+	AddressListGroup                                       = "securitypolicies.vitistack.io"
+	AddressListKind                                        = "AddressList"
+	ConfigMapKind                                          = "ConfigMap"
+	AddressReferenceDNSPrefix                              = "dns:"
+	ResolvConfPath                                         = "/etc/resolv.conf"
+	EventReasonDNSResolutionFailed                         = "DNSResolutionFailed"
+	EventReasonReferenceNotPermitted                       = "ReferenceNotPermitted"
+	EventReasonInvalidConfiguration                        = "InvalidConfiguration"
+	AddressTokenNodes                                      = "@nodes"
+	AddressTokenPodCIDRs                                   = "@pod-cidrs"
+	AddressTokenService                                    = "@service"
+	AddressTokenServicePrefix                              = AddressTokenService + ":"
+	ServiceKind                                            = "Service"
+	RuleModeMerged                                         = "merged"
+	RuleModePerList                                        = "per-list"
+	RuleNameListPrefix                                     = "list-"
+	RuleNameAddresses                                      = "addresses"
+	RuleNameExpression                                     = "expression"
+	RuleNameMaxLength                                      = 253
+	RulesVersionV1                                         = "v1"
+	JWTProviderName                                        = "jwt"
+	ExtAuthProtocolHTTP                                    = "http"
+	ExtAuthProtocolGRPC                                    = "grpc"
+	ProxyProtocolOptional                                  = "optional"
+	RateLimitTypeLocal                                     = "local"
+	RateLimitTypeGlobal                                    = "global"
+)
+
 const (
-	NetworkPoliciesNamespace              = "network-policies"
-	AnnotationSecurityPolicyDefaultAction = "securitypolicies.vitistack.io/default-action"
-	AnnotationSecurityPolicyLists         = "securitypolicies.vitistack.io/lists"
-	AnnotationSecurityPolicyAddresses     = "securitypolicies.vitistack.io/addresses"
-	AnnotationSecurityPolicyLastUpdated   = "securitypolicies.vitistack.io/last-updated"
-	AnnotationSecurityPolicyManagedBy     = "securitypolicies.vitistack.io/managed-by"
-	SecurityPolicyOwner                   = "gatewayapi-securitypolicy-operator"
-	AnnotationSecurityPolicyGateway       = "securitypolicies.vitistack.io/gateway"
-	DefaultAPIGatewayName                 = "envoy-proxy"
-	FinalizerNetworkPolicy                = "networkpolicies.vitistack.io/finalizer"
-	FinalizerSecurityPolicy               = "securitypolicies.vitistack.io/finalizer"
+	RemoteListFetchTimeout           = 30 * time.Second
+	RemoteListMaxBytes               = 10 << 20
+	DefaultRemoteListRefreshInterval = time.Hour
+	DNSLookupTimeout                 = 5 * time.Second
+	DefaultDNSMinTTL                 = 30 * time.Second
+	DefaultDNSMaxTTL                 = time.Hour
+	DNSRefreshCheckInterval          = 5 * time.Second
 )
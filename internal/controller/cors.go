@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"golang.org/x/net/http/httpguts"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// corsOriginPattern matches the origins accepted by Envoy Gateway: "*", or a scheme
+// and host with an optional port, where the host may be "*" or start with "*.".
+var corsOriginPattern = regexp.MustCompile(`^(\*|https?:\/\/(\*|(\*\.)?(([\w-]+\.?)+)?[\w-]+)(:\d{1,5})?)$`)
+
+// corsMaxAgePattern matches a Gateway API duration, e.g. 1h or 10m30s.
+var corsMaxAgePattern = regexp.MustCompile(`^([0-9]{1,5}(h|m|s|ms)){1,4}$`)
+
+// parseCORSAnnotations returns the CORS configuration of the SecurityPolicy described by the cors-
+// annotations, or nil if no origins are allowed.
+func parseCORSAnnotations(annotations map[string]string) (*envoyv1.CORS, error) {
+	origins := annotationEntries(annotations, AnnotationSecurityPolicyCORSAllowOrigins)
+	if len(origins) == 0 {
+		for _, annotation := range []string{
+			AnnotationSecurityPolicyCORSAllowMethods,
+			AnnotationSecurityPolicyCORSAllowHeaders,
+			AnnotationSecurityPolicyCORSExposeHeaders,
+			AnnotationSecurityPolicyCORSMaxAge,
+			AnnotationSecurityPolicyCORSAllowCredentials,
+		} {
+			if annotations[annotation] != "" {
+				return nil, fmt.Errorf("%s requires %s", annotation, AnnotationSecurityPolicyCORSAllowOrigins)
+			}
+		}
+		return nil, nil
+	}
+
+	cors := &envoyv1.CORS{}
+	for _, origin := range origins {
+		if len(origin) > 253 || !corsOriginPattern.MatchString(origin) {
+			return nil, fmt.Errorf("%s not valid: %q. Expected *, or a scheme and host such as https://*.example.com", AnnotationSecurityPolicyCORSAllowOrigins, origin)
+		}
+		cors.AllowOrigins = append(cors.AllowOrigins, envoyv1.Origin(origin))
+	}
+
+	for _, method := range annotationEntries(annotations, AnnotationSecurityPolicyCORSAllowMethods) {
+		if method != "*" && !httpguts.ValidHeaderFieldName(method) {
+			return nil, fmt.Errorf("%s not valid: %q is not a valid method", AnnotationSecurityPolicyCORSAllowMethods, method)
+		}
+		cors.AllowMethods = append(cors.AllowMethods, method)
+	}
+
+	var err error
+	if cors.AllowHeaders, err = parseCORSHeaders(annotations, AnnotationSecurityPolicyCORSAllowHeaders); err != nil {
+		return nil, err
+	}
+	if cors.ExposeHeaders, err = parseCORSHeaders(annotations, AnnotationSecurityPolicyCORSExposeHeaders); err != nil {
+		return nil, err
+	}
+
+	if value := strings.TrimSpace(annotations[AnnotationSecurityPolicyCORSMaxAge]); value != "" {
+		if _, err := time.ParseDuration(value); err != nil || !corsMaxAgePattern.MatchString(value) {
+			return nil, fmt.Errorf("%s not valid: %q. Expected a duration such as 10m or 1h", AnnotationSecurityPolicyCORSMaxAge, value)
+		}
+		maxAge := gatewayv1.Duration(value)
+		cors.MaxAge = &maxAge
+	}
+
+	if value, ok := annotations[AnnotationSecurityPolicyCORSAllowCredentials]; ok {
+		allowCredentials, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s not valid: %q. Valid values: true || false", AnnotationSecurityPolicyCORSAllowCredentials, value)
+		}
+		// Browsers reject credentialed responses for a wildcard origin
+		if allowCredentials && slices.Contains(origins, "*") {
+			return nil, fmt.Errorf("%s cannot be true when %s contains *", AnnotationSecurityPolicyCORSAllowCredentials, AnnotationSecurityPolicyCORSAllowOrigins)
+		}
+		cors.AllowCredentials = &allowCredentials
+	}
+
+	return cors, nil
+}
+
+// parseCORSHeaders returns the comma separated header names of the annotation,
+// "*" allows all headers.
+func parseCORSHeaders(annotations map[string]string, annotation string) ([]string, error) {
+	headers := annotationEntries(annotations, annotation)
+	for _, header := range headers {
+		if header != "*" && !httpguts.ValidHeaderFieldName(header) {
+			return nil, fmt.Errorf("%s not valid: %q is not a valid header name", annotation, header)
+		}
+	}
+	return headers, nil
+}
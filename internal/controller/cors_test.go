@@ -0,0 +1,37 @@
+package controller
+
+import "testing"
+
+func TestParseCORSAnnotations(t *testing.T) {
+	if cors, err := parseCORSAnnotations(map[string]string{}); cors != nil || err != nil {
+		t.Errorf("got %+v, %v, want no CORS", cors, err)
+	}
+
+	cors, err := parseCORSAnnotations(map[string]string{
+		AnnotationSecurityPolicyCORSAllowOrigins:     "https://app.example.com, https://*.example.com:8443",
+		AnnotationSecurityPolicyCORSAllowMethods:     "GET,POST",
+		AnnotationSecurityPolicyCORSAllowHeaders:     "content-type,x-request-id",
+		AnnotationSecurityPolicyCORSExposeHeaders:    "*",
+		AnnotationSecurityPolicyCORSMaxAge:           "10m",
+		AnnotationSecurityPolicyCORSAllowCredentials: "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cors.AllowOrigins) != 2 || len(cors.AllowMethods) != 2 || len(cors.AllowHeaders) != 2 || *cors.MaxAge != "10m" || !*cors.AllowCredentials {
+		t.Errorf("got %+v", cors)
+	}
+
+	for _, annotations := range []map[string]string{
+		{AnnotationSecurityPolicyCORSAllowOrigins: "app.example.com"},
+		{AnnotationSecurityPolicyCORSAllowOrigins: "https://app.*.example.com"},
+		{AnnotationSecurityPolicyCORSAllowOrigins: "*", AnnotationSecurityPolicyCORSAllowCredentials: "true"},
+		{AnnotationSecurityPolicyCORSAllowOrigins: "*", AnnotationSecurityPolicyCORSMaxAge: "10 minutes"},
+		{AnnotationSecurityPolicyCORSAllowOrigins: "*", AnnotationSecurityPolicyCORSAllowHeaders: "x header"},
+		{AnnotationSecurityPolicyCORSAllowMethods: "GET"},
+	} {
+		if _, err := parseCORSAnnotations(annotations); err == nil {
+			t.Errorf("expected error for %v", annotations)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
@@ -22,11 +23,21 @@ func createSecurityPolicy(ctx context.Context, r client.Client, gatewayApiResour
 			},
 		},
 	}
+	if gatewayApiResource.SectionName != "" {
+		sectionName := gatewayv1.SectionName(gatewayApiResource.SectionName)
+		targetRefs[0].SectionName = &sectionName
+	}
 
 	// Check if SecurityPolicy already exists with the given name and overwrite TargetRefs
 	var existingSecurityPolicy envoyv1.SecurityPolicy
 	err := r.Get(ctx, client.ObjectKey{Name: gatewayApiResource.securityPolicyName(), Namespace: gatewayApiResource.Namespace}, &existingSecurityPolicy)
 	if err == nil {
+		// Refuse to take over a SecurityPolicy of another route, gateway or listener
+		for _, targetRef := range existingSecurityPolicy.Spec.TargetRefs {
+			if !gatewayApiResource.targetedBy(targetRef) {
+				return envoyv1.SecurityPolicy{}, fmt.Errorf("SecurityPolicy %s/%s already exists and targets %s %s", existingSecurityPolicy.Namespace, existingSecurityPolicy.Name, targetRef.Kind, targetRef.Name)
+			}
+		}
 		// Overwrite TargetRefs if SecurityPolicy already exists
 		existingSecurityPolicy.Spec.TargetRefs = targetRefs
 		if err := r.Update(ctx, &existingSecurityPolicy); err != nil {
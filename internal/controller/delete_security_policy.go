@@ -22,8 +22,7 @@ func deleteSecurityPolicy(ctx context.Context, r client.Client, gatewayApiResour
 	if len(securityPolicyList.Items) > 0 {
 		for _, securityPolicy := range securityPolicyList.Items {
 			for _, targetRef := range securityPolicy.Spec.TargetRefs {
-				if string(targetRef.Name) == gatewayApiResource.Name &&
-					string(targetRef.Kind) == gatewayApiResource.Kind {
+				if gatewayApiResource.targetedBy(targetRef) {
 					filterSecurityPolicyList = append(filterSecurityPolicyList, securityPolicy)
 				}
 			}
@@ -46,8 +45,7 @@ func deleteSecurityPolicy(ctx context.Context, r client.Client, gatewayApiResour
 				// Remove TargetRef that matches the HTTPRoute's name and kind
 				newTargetRefs := []gatewayv1.LocalPolicyTargetReferenceWithSectionName{}
 				for _, targetRef := range securityPolicy.Spec.TargetRefs {
-					if string(targetRef.Name) != gatewayApiResource.Name ||
-						string(targetRef.Kind) != gatewayApiResource.Kind {
+					if !gatewayApiResource.targetedBy(targetRef) {
 						newTargetRefs = append(newTargetRefs, targetRef)
 					}
 				}
@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+)
+
+// DNSResolver resolves the dns: entries of the addresses annotation and keeps them
+// up to date. Hosts are re-resolved when their TTL expires, bounded by MinTTL and
+// MaxTTL, and the routes and gateways using a host are notified when its answer
+// set changes. Failed lookups keep the previous answer set and are reported as
+// warning events on the routes and gateways using the host.
+type DNSResolver struct {
+	Client   Client
+	Recorder events.EventRecorder
+
+	// MinTTL and MaxTTL bound how long answers are cached
+	MinTTL time.Duration
+	MaxTTL time.Duration
+
+	// Lookup resolves a host, it defaults to lookupHost
+	Lookup func(ctx context.Context, host string) ([]netip.Addr, time.Duration, error)
+
+	mu    sync.Mutex
+	hosts map[string]*resolvedHost
+}
+
+// resolvedHost is the cached answer set of a host and the resources using it.
+type resolvedHost struct {
+	cidrs     []string
+	expires   time.Time
+	consumers map[gatewayApiResource]struct{}
+}
+
+// parseDNSEntry returns the host of a dns: entry and whether the entry is a dns: entry.
+func parseDNSEntry(entry string) (string, bool, error) {
+	host, found := strings.CutPrefix(entry, AddressReferenceDNSPrefix)
+	if !found {
+		return "", false, nil
+	}
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+	if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+		return "", true, fmt.Errorf("invalid host %q in %q: %s", host, entry, strings.Join(errs, ", "))
+	}
+	return host, true, nil
+}
+
+// Resolve returns the CIDRs of each of the given hosts and records that consumer
+// uses them, replacing the hosts it used before, so no hosts forget the consumer.
+// Hosts that have not been resolved yet are resolved immediately. They start from
+// the CIDRs in previous, e.g. those of the SecurityPolicy before a restart, which
+// are kept if the lookup fails. A host that cannot be resolved otherwise has no CIDRs.
+func (d *DNSResolver) Resolve(ctx context.Context, consumer gatewayApiResource, hosts []string, previous map[string][]string) map[string][]string {
+
+	d.mu.Lock()
+	if d.hosts == nil {
+		d.hosts = map[string]*resolvedHost{}
+	}
+
+	// Forget hosts the resource no longer uses
+	for host, resolved := range d.hosts {
+		if !slices.Contains(hosts, host) {
+			delete(resolved.consumers, consumer)
+			if len(resolved.consumers) == 0 {
+				delete(d.hosts, host)
+			}
+		}
+	}
+
+	var unresolved []string
+	for _, host := range hosts {
+		resolved, ok := d.hosts[host]
+		if !ok {
+			resolved = &resolvedHost{cidrs: previous[host], consumers: map[gatewayApiResource]struct{}{}}
+			d.hosts[host] = resolved
+			unresolved = append(unresolved, host)
+		}
+		resolved.consumers[consumer] = struct{}{}
+	}
+	d.mu.Unlock()
+
+	// Resolve new hosts right away, so the first SecurityPolicy already contains them
+	for _, host := range unresolved {
+		d.refreshHost(ctx, host, &consumer)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resolvedHosts := make(map[string][]string, len(hosts))
+	for _, host := range hosts {
+		if resolved, ok := d.hosts[host]; ok {
+			resolvedHosts[host] = resolved.cidrs
+		}
+	}
+
+	return resolvedHosts
+}
+
+// Start re-resolves hosts whose answers have expired until ctx is cancelled.
+// It implements manager.Runnable.
+func (d *DNSResolver) Start(ctx context.Context) error {
+	ticker := time.NewTicker(DNSRefreshCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			d.mu.Lock()
+			var expired []string
+			now := time.Now()
+			for host, resolved := range d.hosts {
+				if now.After(resolved.expires) {
+					expired = append(expired, host)
+				}
+			}
+			d.mu.Unlock()
+
+			for _, host := range expired {
+				d.refreshHost(ctx, host, nil)
+			}
+		}
+	}
+}
+
+// refreshHost resolves host and stores the answer set. The routes and gateways
+// using the host are notified if the answer set changed, except for requester,
+// which receives the answer set directly.
+func (d *DNSResolver) refreshHost(ctx context.Context, host string, requester *gatewayApiResource) {
+	log := logf.FromContext(ctx)
+
+	lookup := d.Lookup
+	if lookup == nil {
+		lookup = lookupHost
+	}
+	addrs, ttl, err := lookup(ctx, host)
+
+	d.mu.Lock()
+	resolved, ok := d.hosts[host]
+	if !ok {
+		// The host is no longer used
+		d.mu.Unlock()
+		return
+	}
+	consumers := make([]gatewayApiResource, 0, len(resolved.consumers))
+	for consumer := range resolved.consumers {
+		consumers = append(consumers, consumer)
+	}
+
+	if err != nil {
+		// Keep the previous answer set and retry after the minimum TTL
+		resolved.expires = time.Now().Add(d.minTTL())
+		d.mu.Unlock()
+
+		log.Info("Unable to resolve host, keeping previous addresses", "Host", host, "Error", err)
+		for _, consumer := range consumers {
+			if requester == nil || consumer == *requester {
+				d.warn(consumer, host, err)
+			}
+		}
+		return
+	}
+
+	var cidrs []string
+	for _, addr := range addrs {
+		cidrs = appendHostCIDR(cidrs, addr.String())
+	}
+	cidrs = utils.SortSlice(cidrs)
+
+	changed := !slices.Equal(resolved.cidrs, cidrs)
+	resolved.cidrs = cidrs
+	resolved.expires = time.Now().Add(min(max(ttl, d.minTTL()), d.maxTTL()))
+	d.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	// Update each consumer to trigger reconciliation
+	for _, consumer := range consumers {
+		if requester != nil && consumer == *requester {
+			continue
+		}
+		object, err := getGatewayApiObject(ctx, d.Client, consumer)
+		if apierrors.IsNotFound(err) {
+			d.forget(consumer, host)
+			continue
+		}
+		if err == nil {
+			err = notifyController(ctx, d.Client, object)
+		}
+		if err != nil {
+			log.Error(err, "Failed to notify "+consumer.Kind, consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+			continue
+		}
+		log.Info("Patched "+consumer.Kind+" due to DNS change", consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name, "Host", host)
+	}
+}
+
+// forget removes consumer from the resources using host.
+func (d *DNSResolver) forget(consumer gatewayApiResource, host string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if resolved, ok := d.hosts[host]; ok {
+		delete(resolved.consumers, consumer)
+		if len(resolved.consumers) == 0 {
+			delete(d.hosts, host)
+		}
+	}
+}
+
+// warn records a warning event on consumer about a failed lookup of host.
+func (d *DNSResolver) warn(consumer gatewayApiResource, host string, err error) {
+	if d.Recorder == nil {
+		return
+	}
+	reference := &corev1.ObjectReference{
+		APIVersion: gatewayv1.GroupVersion.String(),
+		Kind:       consumer.Kind,
+		Namespace:  consumer.Namespace,
+		Name:       consumer.Name,
+	}
+	d.Recorder.Eventf(reference, nil, corev1.EventTypeWarning, EventReasonDNSResolutionFailed, "Resolve",
+		"Unable to resolve %s, keeping previous addresses: %v", host, err)
+}
+
+func (d *DNSResolver) minTTL() time.Duration {
+	if d.MinTTL > 0 {
+		return d.MinTTL
+	}
+	return DefaultDNSMinTTL
+}
+
+func (d *DNSResolver) maxTTL() time.Duration {
+	if d.MaxTTL > 0 {
+		return d.MaxTTL
+	}
+	return DefaultDNSMaxTTL
+}
+
+// getGatewayApiObject fetches the HTTPRoute, GRPCRoute or Gateway identified by gatewayApiResource.
+func getGatewayApiObject(ctx context.Context, r Client, gatewayApiResource gatewayApiResource) (client.Object, error) {
+	var object client.Object
+	switch gatewayApiResource.Kind {
+	case "HTTPRoute":
+		object = &gatewayv1.HTTPRoute{}
+	case "GRPCRoute":
+		object = &gatewayv1.GRPCRoute{}
+	case "Gateway":
+		object = &gatewayv1.Gateway{}
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", gatewayApiResource.Kind)
+	}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: gatewayApiResource.Namespace, Name: gatewayApiResource.Name}, object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
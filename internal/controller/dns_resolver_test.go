@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+)
+
+func TestParseDNSEntry(t *testing.T) {
+	host, ok, err := parseDNSEntry("dns:Partner.Example.com.")
+	if err != nil || !ok || host != "partner.example.com" {
+		t.Errorf("got %q, %v, %v, want partner.example.com", host, ok, err)
+	}
+
+	if _, ok, err := parseDNSEntry("10.0.0.0/8"); ok || err != nil {
+		t.Errorf("CIDR must not be a dns: entry, got %v, %v", ok, err)
+	}
+
+	if _, _, err := parseDNSEntry("dns:partner_example.com"); err == nil {
+		t.Error("expected error for invalid host")
+	}
+}
+
+func TestDNSResolver(t *testing.T) {
+	ctx := context.Background()
+	route := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "partner"}
+
+	var lookupErr error
+	ttl := time.Second
+	resolver := &DNSResolver{
+		MinTTL: time.Minute,
+		MaxTTL: time.Hour,
+		Lookup: func(ctx context.Context, host string) ([]netip.Addr, time.Duration, error) {
+			if lookupErr != nil {
+				return nil, 0, lookupErr
+			}
+			return []netip.Addr{netip.MustParseAddr("2001:db8::1"), netip.MustParseAddr("192.0.2.10")}, ttl, nil
+		},
+	}
+
+	want := []string{"192.0.2.10/32", "2001:db8::1/128"}
+	if got := resolver.Resolve(ctx, route, []string{"partner.example.com"}, nil)["partner.example.com"]; !slices.Equal(got, want) {
+		t.Errorf("Resolve() = %v, want %v", got, want)
+	}
+
+	// TTLs below the minimum are raised to the minimum
+	if expires := time.Until(resolver.hosts["partner.example.com"].expires); expires < 59*time.Second {
+		t.Errorf("expires in %v, want at least the minimum TTL", expires)
+	}
+
+	// Failed lookups keep the previous answer set
+	lookupErr = errors.New("timeout")
+	resolver.refreshHost(ctx, "partner.example.com", &route)
+	if got := resolver.Resolve(ctx, route, []string{"partner.example.com"}, nil)["partner.example.com"]; !slices.Equal(got, want) {
+		t.Errorf("Resolve() after failed lookup = %v, want %v", got, want)
+	}
+
+	// Hosts no longer used by any resource are forgotten
+	resolver.Resolve(ctx, route, nil, nil)
+	if len(resolver.hosts) != 0 {
+		t.Errorf("hosts = %v, want none", resolver.hosts)
+	}
+}
+
+func TestDNSResolverPreviousAnswers(t *testing.T) {
+	ctx := context.Background()
+	route := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "partner"}
+	resolver := &DNSResolver{
+		Lookup: func(ctx context.Context, host string) ([]netip.Addr, time.Duration, error) {
+			return nil, 0, errors.New("timeout")
+		},
+	}
+
+	// A failed first lookup keeps the answers recorded on the SecurityPolicy
+	previous := map[string][]string{"partner.example.com": {"192.0.2.10/32"}}
+	if got := resolver.Resolve(ctx, route, []string{"partner.example.com"}, previous)["partner.example.com"]; !slices.Equal(got, previous["partner.example.com"]) {
+		t.Errorf("Resolve() = %v, want %v", got, previous["partner.example.com"])
+	}
+
+	// The SecurityPolicy records the answers for the next restart
+	securityPolicy := &envoyv1.SecurityPolicy{}
+	if err := setResolvedHostsAnnotation(securityPolicy, previous); err != nil {
+		t.Fatal(err)
+	}
+	if got := parseResolvedHostsAnnotation(securityPolicy.Annotations); !slices.Equal(got["partner.example.com"], previous["partner.example.com"]) {
+		t.Errorf("parseResolvedHostsAnnotation() = %v, want %v", got, previous)
+	}
+	if err := setResolvedHostsAnnotation(securityPolicy, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := securityPolicy.Annotations[AnnotationSecurityPolicyResolvedHosts]; ok {
+		t.Errorf("expected annotation to be removed, got %v", securityPolicy.Annotations)
+	}
+
+	forgetDNSHosts(ctx, resolver, route)
+	if len(resolver.hosts) != 0 {
+		t.Errorf("hosts = %v, want none", resolver.hosts)
+	}
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+)
+
+// evaluateExpression evaluates the set expression of the expression annotation and
+// returns the resulting CIDRs. Operands are literal CIDRs, reserved address tokens
+// such as "@nodes", or list references in the same forms as the lists annotation.
+func evaluateExpression(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, annotation string) ([]string, error) {
+
+	expression, err := utils.ParseExpression(annotation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", annotation, err)
+	}
+
+	result, err := utils.EvaluateExpression(expression, func(operand utils.ExpressionOperand) (*utils.PrefixSet, error) {
+		cidrs, err := resolveExpressionOperand(ctx, r, gatewayApiResource, operand.Value)
+		if err != nil {
+			return nil, &utils.ExpressionError{Pos: operand.Pos, Message: err.Error()}
+		}
+		return utils.NewPrefixSet(cidrs...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate expression %q: %w", annotation, err)
+	}
+
+	return result.Strings(), nil
+}
+
+// resolveExpressionOperand returns the CIDRs of an operand of a set expression.
+func resolveExpressionOperand(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, operand string) ([]string, error) {
+
+	// Literal CIDR
+	if _, err := netip.ParsePrefix(operand); err == nil {
+		return []string{operand}, nil
+	}
+
+	// Reserved address token
+	token, isToken, err := parseAddressToken(operand, gatewayApiResource.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if isToken {
+		return resolveAddressToken(ctx, r, gatewayApiResource, token)
+	}
+
+	if strings.HasPrefix(operand, AddressReferenceDNSPrefix) {
+		return nil, fmt.Errorf("%q: DNS entries are not supported in expressions, use the addresses annotation", operand)
+	}
+
+	// List reference
+	reference, err := parseListReference(operand, gatewayApiResource.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return resolveList(ctx, r, gatewayApiResource, reference)
+}
+
+// expressionOperands returns the operands of the expression annotation value,
+// or nil if it cannot be parsed.
+func expressionOperands(annotation string) []string {
+	if strings.TrimSpace(annotation) == "" {
+		return nil
+	}
+	expression, err := utils.ParseExpression(annotation)
+	if err != nil {
+		return nil
+	}
+	var operands []string
+	for _, operand := range utils.ExpressionOperands(expression) {
+		operands = append(operands, operand.Value)
+	}
+	return operands
+}
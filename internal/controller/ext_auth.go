@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"golang.org/x/net/http/httpguts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// getExtAuth returns the external authorization of the SecurityPolicy described by the
+// ext-auth- annotations, or nil if no Service is referenced. The Service must exist in
+// the namespace of the route or gateway and expose the port. The port may be omitted
+// if the Service has a single port.
+func getExtAuth(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, annotations map[string]string) (*envoyv1.ExtAuth, error) {
+	serviceName := strings.TrimSpace(annotations[AnnotationSecurityPolicyExtAuthService])
+	if serviceName == "" {
+		return nil, nil
+	}
+	if errs := validation.IsDNS1035Label(serviceName); len(errs) > 0 {
+		return nil, fmt.Errorf("%s not valid: %q: %s", AnnotationSecurityPolicyExtAuthService, serviceName, strings.Join(errs, ", "))
+	}
+
+	var service corev1.Service
+	if err := r.Get(ctx, client.ObjectKey{Name: serviceName, Namespace: gatewayApiResource.Namespace}, &service); err != nil {
+		return nil, fmt.Errorf("unable to get external authorization Service %s/%s: %w", gatewayApiResource.Namespace, serviceName, err)
+	}
+
+	var port int32
+	if value := strings.TrimSpace(annotations[AnnotationSecurityPolicyExtAuthPort]); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || validation.IsValidPortNum(int(parsed)) != nil {
+			return nil, fmt.Errorf("%s not valid: %q. Expected a port number", AnnotationSecurityPolicyExtAuthPort, value)
+		}
+		port = int32(parsed)
+		if !slices.ContainsFunc(service.Spec.Ports, func(servicePort corev1.ServicePort) bool { return servicePort.Port == port }) {
+			return nil, fmt.Errorf("external authorization Service %s/%s has no port %d", service.Namespace, service.Name, port)
+		}
+	} else if len(service.Spec.Ports) == 1 {
+		port = service.Spec.Ports[0].Port
+	} else {
+		return nil, fmt.Errorf("%s is required, external authorization Service %s/%s has %d ports", AnnotationSecurityPolicyExtAuthPort, service.Namespace, service.Name, len(service.Spec.Ports))
+	}
+
+	portNumber := gatewayv1.PortNumber(port)
+	backend := envoyv1.BackendCluster{
+		BackendRefs: []envoyv1.BackendRef{{
+			BackendObjectReference: gatewayv1.BackendObjectReference{
+				Name: gatewayv1.ObjectName(serviceName),
+				Port: &portNumber,
+			},
+		}},
+	}
+
+	extAuth := &envoyv1.ExtAuth{}
+	headersToBackend, err := parseExtAuthHeaders(annotations, AnnotationSecurityPolicyExtAuthHeadersToBackend)
+	if err != nil {
+		return nil, err
+	}
+	switch protocol := annotations[AnnotationSecurityPolicyExtAuthProtocol]; protocol {
+	case "", ExtAuthProtocolHTTP:
+		extAuth.HTTP = &envoyv1.HTTPExtAuthService{BackendCluster: backend, HeadersToBackend: headersToBackend}
+		if path := strings.TrimSpace(annotations[AnnotationSecurityPolicyExtAuthPath]); path != "" {
+			if !strings.HasPrefix(path, "/") {
+				return nil, fmt.Errorf("%s not valid: %q. Expected a path starting with /", AnnotationSecurityPolicyExtAuthPath, path)
+			}
+			extAuth.HTTP.Path = &path
+		}
+	case ExtAuthProtocolGRPC:
+		if annotations[AnnotationSecurityPolicyExtAuthPath] != "" || len(headersToBackend) > 0 {
+			return nil, fmt.Errorf("%s and %s are only supported with protocol %s", AnnotationSecurityPolicyExtAuthPath, AnnotationSecurityPolicyExtAuthHeadersToBackend, ExtAuthProtocolHTTP)
+		}
+		extAuth.GRPC = &envoyv1.GRPCExtAuthService{BackendCluster: backend}
+	default:
+		return nil, fmt.Errorf("%s not valid: %q. Valid values: %s || %s", AnnotationSecurityPolicyExtAuthProtocol, protocol, ExtAuthProtocolHTTP, ExtAuthProtocolGRPC)
+	}
+
+	if extAuth.HeadersToExtAuth, err = parseExtAuthHeaders(annotations, AnnotationSecurityPolicyExtAuthHeaders); err != nil {
+		return nil, err
+	}
+
+	if value, ok := annotations[AnnotationSecurityPolicyExtAuthFailOpen]; ok {
+		failOpen, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s not valid: %q. Valid values: true || false", AnnotationSecurityPolicyExtAuthFailOpen, value)
+		}
+		extAuth.FailOpen = &failOpen
+	}
+
+	return extAuth, nil
+}
+
+// parseExtAuthHeaders returns the comma separated header names of the annotation.
+func parseExtAuthHeaders(annotations map[string]string, annotation string) ([]string, error) {
+	headers := annotationEntries(annotations, annotation)
+	for _, header := range headers {
+		if !httpguts.ValidHeaderFieldName(header) {
+			return nil, fmt.Errorf("%s not valid: %q is not a valid header name", annotation, header)
+		}
+	}
+	return headers, nil
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetExtAuth(t *testing.T) {
+	ctx := context.Background()
+	route := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "app"}
+	r := fake.NewClientBuilder().WithObjects(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "authz"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 8080}, {Name: "grpc", Port: 9000}}},
+	}).Build()
+
+	extAuth, err := getExtAuth(ctx, r, route, map[string]string{
+		AnnotationSecurityPolicyExtAuthService:  "authz",
+		AnnotationSecurityPolicyExtAuthPort:     "9000",
+		AnnotationSecurityPolicyExtAuthProtocol: "grpc",
+		AnnotationSecurityPolicyExtAuthHeaders:  "authorization,x-request-id",
+		AnnotationSecurityPolicyExtAuthFailOpen: "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extAuth.GRPC == nil || *extAuth.GRPC.BackendRefs[0].Port != 9000 || len(extAuth.HeadersToExtAuth) != 2 || !*extAuth.FailOpen {
+		t.Errorf("got %+v", extAuth)
+	}
+
+	for _, annotations := range []map[string]string{
+		{AnnotationSecurityPolicyExtAuthService: "missing", AnnotationSecurityPolicyExtAuthPort: "8080"},
+		{AnnotationSecurityPolicyExtAuthService: "authz"},
+		{AnnotationSecurityPolicyExtAuthService: "authz", AnnotationSecurityPolicyExtAuthPort: "8081"},
+		{AnnotationSecurityPolicyExtAuthService: "authz", AnnotationSecurityPolicyExtAuthPort: "8080", AnnotationSecurityPolicyExtAuthProtocol: "tcp"},
+		{AnnotationSecurityPolicyExtAuthService: "authz", AnnotationSecurityPolicyExtAuthPort: "9000", AnnotationSecurityPolicyExtAuthProtocol: "grpc", AnnotationSecurityPolicyExtAuthPath: "/check"},
+	} {
+		if _, err := getExtAuth(ctx, r, route, annotations); err == nil {
+			t.Errorf("expected error for %v", annotations)
+		}
+	}
+}
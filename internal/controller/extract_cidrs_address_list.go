@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"fmt"
+
+	securitypoliciesv1alpha1 "github.com/vitistack/gatewayapi-securitypolicy-operator/api/v1alpha1"
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+)
+
+// extractCIDRsFromAddressList extracts all unique CIDRs from the given AddressList's entries
+// and the entries fetched from its external source.
+// It appends any new CIDRs found to the provided cidrs slice and returns the updated slice.
+// An error is returned if an entry is not a valid CIDR.
+
+func extractCIDRsFromAddressList(addressList *securitypoliciesv1alpha1.AddressList, cidrs []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(cidrs))
+	for _, c := range cidrs {
+		seen[c] = struct{}{}
+	}
+
+	for _, entry := range addressList.Spec.Entries {
+		if !utils.CheckValidCIDR(entry.CIDR) {
+			return nil, fmt.Errorf("invalid CIDR %q in AddressList %q", entry.CIDR, addressList.Name)
+		}
+		if _, exists := seen[entry.CIDR]; !exists {
+			cidrs = append(cidrs, entry.CIDR)
+			seen[entry.CIDR] = struct{}{}
+		}
+	}
+
+	// Append entries fetched from the external source
+	if addressList.Status.Source != nil {
+		for _, c := range addressList.Status.Source.Entries {
+			if !utils.CheckValidCIDR(c) {
+				return nil, fmt.Errorf("invalid CIDR %q fetched for AddressList %q", c, addressList.Name)
+			}
+			if _, exists := seen[c]; !exists {
+				cidrs = append(cidrs, c)
+				seen[c] = struct{}{}
+			}
+		}
+	}
+
+	return cidrs, nil
+}
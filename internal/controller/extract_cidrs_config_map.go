@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// extractCIDRsFromConfigMap extracts all unique CIDRs from the data keys of the given ConfigMap.
+// Each value holds newline- or comma-separated CIDRs, '#' starts a comment.
+// It appends any new CIDRs found to the provided cidrs slice and returns the updated slice.
+
+func extractCIDRsFromConfigMap(configMap *corev1.ConfigMap, cidrs []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(cidrs))
+	for _, c := range cidrs {
+		seen[c] = struct{}{}
+	}
+
+	// Process keys in sorted order so errors are reported deterministically
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		parsed, err := utils.ParseCIDRList(configMap.Data[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid data in ConfigMap %s/%s key %q: %w", configMap.Namespace, configMap.Name, key, err)
+		}
+		for _, c := range parsed {
+			if _, exists := seen[c]; !exists {
+				cidrs = append(cidrs, c)
+				seen[c] = struct{}{}
+			}
+		}
+	}
+
+	return cidrs, nil
+}
@@ -1,13 +1,19 @@
 package controller
 
 import (
+	"fmt"
+
 	networkingv1 "k8s.io/api/networking/v1"
+
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
 )
 
 // extractCIDRsFromNetworkPolicy extracts all unique CIDRs from the given NetworkPolicy's ingress rules.
+// The except ranges of an ipBlock are removed from its CIDR, so the result covers exactly the
+// addresses the ipBlock allows.
 // It appends any new CIDRs found to the provided cidrs slice and returns the updated slice.
 
-func extractCIDRsFromNetworkPolicy(np *networkingv1.NetworkPolicy, cidrs []string) []string {
+func extractCIDRsFromNetworkPolicy(np *networkingv1.NetworkPolicy, cidrs []string) ([]string, error) {
 	seen := make(map[string]struct{}, len(cidrs))
 	for _, c := range cidrs {
 		seen[c] = struct{}{}
@@ -16,14 +22,23 @@ func extractCIDRsFromNetworkPolicy(np *networkingv1.NetworkPolicy, cidrs []strin
 	for _, ingress := range np.Spec.Ingress {
 		for _, from := range ingress.From {
 			if from.IPBlock != nil && from.IPBlock.CIDR != "" {
-				c := from.IPBlock.CIDR
-				if _, exists := seen[c]; !exists {
-					cidrs = append(cidrs, c)
-					seen[c] = struct{}{}
+				block, err := utils.NewPrefixSet(from.IPBlock.CIDR)
+				if err != nil {
+					return nil, fmt.Errorf("invalid ipBlock in NetworkPolicy %s/%s: %w", np.Namespace, np.Name, err)
+				}
+				except, err := utils.NewPrefixSet(from.IPBlock.Except...)
+				if err != nil {
+					return nil, fmt.Errorf("invalid ipBlock except in NetworkPolicy %s/%s: %w", np.Namespace, np.Name, err)
+				}
+				for _, c := range block.Subtract(except).Strings() {
+					if _, exists := seen[c]; !exists {
+						cidrs = append(cidrs, c)
+						seen[c] = struct{}{}
+					}
 				}
 			}
 		}
 	}
 
-	return cidrs
+	return cidrs, nil
 }
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"slices"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func TestExtractCIDRsFromNetworkPolicy(t *testing.T) {
+	networkPolicy := func(blocks ...networkingv1.IPBlock) *networkingv1.NetworkPolicy {
+		var peers []networkingv1.NetworkPolicyPeer
+		for _, block := range blocks {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: &block})
+		}
+		return &networkingv1.NetworkPolicy{Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{From: peers}},
+		}}
+	}
+
+	cidrs, err := extractCIDRsFromNetworkPolicy(networkPolicy(
+		networkingv1.IPBlock{CIDR: "10.0.0.0/14", Except: []string{"10.1.0.0/16"}},
+		networkingv1.IPBlock{CIDR: "192.0.2.0/24"},
+	), []string{"192.0.2.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"192.0.2.0/24", "10.0.0.0/16", "10.2.0.0/15"}
+	if !slices.Equal(cidrs, want) {
+		t.Errorf("got %v, want %v", cidrs, want)
+	}
+
+	if _, err := extractCIDRsFromNetworkPolicy(networkPolicy(
+		networkingv1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.66.0.0"}},
+	), nil); err == nil {
+		t.Error("expected error for invalid except CIDR")
+	}
+}
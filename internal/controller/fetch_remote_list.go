@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	securitypoliciesv1alpha1 "github.com/vitistack/gatewayapi-securitypolicy-operator/api/v1alpha1"
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+)
+
+// remoteList is the result of fetching a remote list.
+// CIDRs is only set when NotModified is false.
+type remoteList struct {
+	NotModified  bool
+	CIDRs        []string
+	ETag         string
+	LastModified string
+}
+
+// NewRemoteListClient returns the HTTP client used to fetch remote lists.
+// file:// URLs are served from fileRoot, so lists can be read from mounted volumes
+// without exposing the rest of the operator's filesystem.
+func NewRemoteListClient(fileRoot string) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if fileRoot != "" {
+		transport.RegisterProtocol("file", http.NewFileTransport(http.Dir(fileRoot)))
+	}
+	return &http.Client{Transport: transport}
+}
+
+// fetchRemoteList fetches the list published by source and parses it in the format
+// of the source. The etag and lastModified values of a previous fetch are sent as
+// If-None-Match and If-Modified-Since, so an unchanged list is reported as
+// NotModified without being downloaded.
+func fetchRemoteList(ctx context.Context, httpClient *http.Client, source *securitypoliciesv1alpha1.HTTPAddressListSource, etag string, lastModified string) (remoteList, error) {
+
+	url := source.URL
+
+	ctx, cancel := context.WithTimeout(ctx, RemoteListFetchTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return remoteList{}, fmt.Errorf("invalid request for %q: %w", url, err)
+	}
+	request.Header.Set("User-Agent", SecurityPolicyOwner)
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		request.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return remoteList{}, fmt.Errorf("unable to fetch %q: %w", url, err)
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	switch {
+	case response.StatusCode == http.StatusNotModified:
+		return remoteList{NotModified: true, ETag: etag, LastModified: lastModified}, nil
+	case response.StatusCode < 200 || response.StatusCode > 299:
+		return remoteList{}, fmt.Errorf("unable to fetch %q: unexpected status %s", url, response.Status)
+	}
+
+	// Limit the size of the body to protect the operator from huge responses
+	body, err := io.ReadAll(io.LimitReader(response.Body, RemoteListMaxBytes+1))
+	if err != nil {
+		return remoteList{}, fmt.Errorf("unable to read %q: %w", url, err)
+	}
+	if len(body) > RemoteListMaxBytes {
+		return remoteList{}, fmt.Errorf("list at %q exceeds %d bytes", url, RemoteListMaxBytes)
+	}
+
+	cidrs, err := utils.ParseIPRanges(string(source.Format), body, source.Services, source.Regions)
+	if err != nil {
+		return remoteList{}, fmt.Errorf("invalid list at %q: %w", url, err)
+	}
+
+	return remoteList{
+		CIDRs:        utils.SortSlice(cidrs),
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// hashCIDRs returns the hex encoded SHA-256 hash of the sorted CIDRs.
+func hashCIDRs(cidrs []string) string {
+	sorted := slices.Clone(cidrs)
+	slices.Sort(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	securitypoliciesv1alpha1 "github.com/vitistack/gatewayapi-securitypolicy-operator/api/v1alpha1"
+)
+
+func TestFetchRemoteList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/list":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte("# office\n10.0.0.0/8\n192.168.0.0/16, 172.16.0.0/12 # vpn\n\n"))
+		case "/invalid":
+			_, _ = w.Write([]byte("10.0.0.0/8\nnot-a-cidr\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	fetched, err := fetchRemoteList(ctx, server.Client(), &securitypoliciesv1alpha1.HTTPAddressListSource{URL: server.URL + "/list"}, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+	if fetched.NotModified || !slices.Equal(fetched.CIDRs, want) || fetched.ETag != `"v1"` {
+		t.Errorf("got %+v, want CIDRs %v with ETag %q", fetched, want, `"v1"`)
+	}
+
+	fetched, err = fetchRemoteList(ctx, server.Client(), &securitypoliciesv1alpha1.HTTPAddressListSource{URL: server.URL + "/list"}, `"v1"`, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fetched.NotModified || fetched.ETag != `"v1"` {
+		t.Errorf("got %+v, want NotModified with ETag %q", fetched, `"v1"`)
+	}
+
+	if _, err := fetchRemoteList(ctx, server.Client(), &securitypoliciesv1alpha1.HTTPAddressListSource{URL: server.URL + "/missing"}, "", ""); err == nil {
+		t.Error("expected error for unexpected status")
+	}
+
+	if _, err := fetchRemoteList(ctx, server.Client(), &securitypoliciesv1alpha1.HTTPAddressListSource{URL: server.URL + "/invalid"}, "", ""); err == nil {
+		t.Error("expected error for invalid CIDR")
+	}
+}
+
+func TestFetchRemoteListFromFile(t *testing.T) {
+	root := t.TempDir()
+	document := `{"prefixes":[
+		{"ip_prefix":"3.5.140.0/22","region":"ap-northeast-2","service":"AMAZON"},
+		{"ip_prefix":"13.32.0.0/15","region":"GLOBAL","service":"CLOUDFRONT"}
+	],"ipv6_prefixes":[
+		{"ipv6_prefix":"2600:9000::/28","region":"GLOBAL","service":"CLOUDFRONT"}
+	]}`
+	if err := os.WriteFile(filepath.Join(root, "ip-ranges.json"), []byte(document), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fetched, err := fetchRemoteList(context.Background(), NewRemoteListClient(root), &securitypoliciesv1alpha1.HTTPAddressListSource{
+		URL:      "file:///ip-ranges.json",
+		Format:   securitypoliciesv1alpha1.AddressListSourceFormatAWS,
+		Services: []string{"cloudfront"},
+	}, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"13.32.0.0/15", "2600:9000::/28"}
+	if !slices.Equal(fetched.CIDRs, want) || fetched.LastModified == "" {
+		t.Errorf("got %+v, want CIDRs %v with Last-Modified", fetched, want)
+	}
+}
+
+func TestHashCIDRs(t *testing.T) {
+	if hashCIDRs([]string{"10.0.0.0/8", "192.168.0.0/16"}) != hashCIDRs([]string{"192.168.0.0/16", "10.0.0.0/8"}) {
+		t.Error("hash must not depend on order")
+	}
+	if hashCIDRs([]string{"10.0.0.0/8"}) == hashCIDRs([]string{"10.0.0.0/16"}) {
+		t.Error("hash must differ for different entries")
+	}
+}
@@ -18,10 +18,13 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -35,12 +38,21 @@ import (
 // GatewayReconciler reconciles a gateway object
 type GatewayReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme      *runtime.Scheme
+	DNSResolver *DNSResolver
+	Recorder    events.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=securitypolicies,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=clienttrafficpolicies;backendtrafficpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes;services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
+// +kubebuilder:rbac:groups=securitypolicies.vitistack.io,resources=addresslists,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -76,9 +88,7 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		// then let's add the finalizer and update the object. This is equivalent
 		// to registering our finalizer.
 		if !controllerutil.ContainsFinalizer(&gateway, FinalizerSecurityPolicy) &&
-			(gateway.Annotations[AnnotationSecurityPolicyDefaultAction] != "" ||
-				gateway.Annotations[AnnotationSecurityPolicyLists] != "" ||
-				gateway.Annotations[AnnotationSecurityPolicyAddresses] != "") {
+			hasGatewayAnnotations(gateway.Annotations) {
 			log.Info("Add Finalizer", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name)
 			controllerutil.AddFinalizer(&gateway, FinalizerSecurityPolicy)
 			if err := r.Update(ctx, &gateway); err != nil {
@@ -96,6 +106,19 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 				return ctrl.Result{}, err
 			}
 		}
+		if err := deleteClientTrafficPolicy(ctx, r.Client, gatewayApiResource); err != nil {
+			log.Info("Failed to delete ClientTrafficPolicy", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+			return ctrl.Result{}, err
+		}
+		if err := deleteBackendTrafficPolicy(ctx, r.Client, gatewayApiResource); err != nil {
+			log.Info("Failed to delete BackendTrafficPolicy", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+			return ctrl.Result{}, err
+		}
+		if err := deleteListenerSecurityPolicies(ctx, r.Client, r.DNSResolver, gatewayApiResource); err != nil {
+			log.Info("Failed to delete listener SecurityPolicies", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+			return ctrl.Result{}, err
+		}
+		forgetDNSHosts(ctx, r.DNSResolver, gatewayApiResource)
 		// remove our finalizer from the list and update it.
 		controllerutil.RemoveFinalizer(&gateway, FinalizerSecurityPolicy)
 		if err := r.Update(ctx, &gateway); err != nil {
@@ -106,9 +129,9 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	// Delete SecurityPolicy if relevant annotations are removed from Gateway
-	if gateway.Annotations[AnnotationSecurityPolicyDefaultAction] == "" &&
-		gateway.Annotations[AnnotationSecurityPolicyLists] == "" &&
-		gateway.Annotations[AnnotationSecurityPolicyAddresses] == "" {
+	if !hasSecurityPolicyAnnotations(gateway.Annotations) {
+		forgetDNSHosts(ctx, r.DNSResolver, gatewayApiResource)
+
 		// Only delete if a SecurityPolicy actually exists
 		if _, err := getSecurityPolicy(ctx, r.Client, gatewayApiResource); err == nil {
 			log.Info("Relevant annotations removed from Gateway, deleting associated SecurityPolicy", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name)
@@ -118,6 +141,22 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			}
 			log.Info("Deleted SecurityPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name)
 		}
+	}
+
+	// Delete the other policies and stop if no relevant annotations are left on Gateway
+	if !hasGatewayAnnotations(gateway.Annotations) {
+		if err := deleteClientTrafficPolicy(ctx, r.Client, gatewayApiResource); err != nil {
+			log.Info("Failed to delete ClientTrafficPolicy", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+			return ctrl.Result{}, err
+		}
+		if err := deleteBackendTrafficPolicy(ctx, r.Client, gatewayApiResource); err != nil {
+			log.Info("Failed to delete BackendTrafficPolicy", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+			return ctrl.Result{}, err
+		}
+		if err := deleteListenerSecurityPolicies(ctx, r.Client, r.DNSResolver, gatewayApiResource); err != nil {
+			log.Info("Failed to delete listener SecurityPolicies", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+			return ctrl.Result{}, err
+		}
 
 		// Remove our finalizer and the annotations we manage.
 		// Only patch when there is something to remove, otherwise the resulting
@@ -139,29 +178,57 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil
 	}
 
-	// Get SecurityPolicy associated with this gateway
-	securityPolicy, err := getSecurityPolicy(ctx, r.Client, gatewayApiResource)
-	if err != nil {
-		log.Info("Unable to fetch SecurityPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
-	}
+	// Get annotations from gateway
+	annotations := gateway.Annotations
+
+	// The SecurityPolicy of the gateway, the SecurityPolicies of its listeners, the
+	// ClientTrafficPolicy and the BackendTrafficPolicy are reconciled independently,
+	// an error in one of them does not hold back the others.
+	var errs []error
 
-	// Create SecurityPolicy if it does not exist
-	if securityPolicy.Name == "" {
-		securityPolicy, err = createSecurityPolicy(ctx, r.Client, gatewayApiResource)
+	if hasSecurityPolicyAnnotations(annotations) {
+		// Get SecurityPolicy associated with this gateway
+		securityPolicy, err := getSecurityPolicy(ctx, r.Client, gatewayApiResource)
 		if err != nil {
-			log.Info("Unable to create SecurityPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
-			return ctrl.Result{}, err
+			log.Info("Unable to fetch SecurityPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+		}
+
+		// Create SecurityPolicy if it does not exist
+		if securityPolicy.Name == "" {
+			securityPolicy, err = createSecurityPolicy(ctx, r.Client, gatewayApiResource)
+			if err != nil {
+				log.Info("Unable to create SecurityPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+				errs = append(errs, err)
+			} else {
+				log.Info("Created SecurityPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name)
+			}
+		}
+
+		// Update SecurityPolicy based on annotations
+		if securityPolicy.Name != "" {
+			if err := updateSecurityPolicy(ctx, r.Client, r.DNSResolver, gatewayApiResource, securityPolicy, annotations); err != nil {
+				log.Info("Update SecurityPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+				errs = append(errs, fmt.Errorf("SecurityPolicy: %w", err))
+			}
 		}
-		log.Info("Created SecurityPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name)
 	}
 
-	// Get annotations from gateway
-	annotations := gateway.Annotations
+	// Create, update or delete the SecurityPolicies of the listeners
+	if err := reconcileListenerSecurityPolicies(ctx, r.Client, r.DNSResolver, gatewayApiResource, gateway.Spec.Listeners, annotations); err != nil {
+		log.Info("Update listener SecurityPolicies for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+		errs = append(errs, err)
+	}
 
-	// Update SecurityPolicy based on annotations
-	if err := updateSecurityPolicy(ctx, r.Client, securityPolicy, annotations); err != nil {
-		log.Info("Update SecurityPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
-		return ctrl.Result{}, nil
+	// Create, update or delete ClientTrafficPolicy based on annotations
+	if err := reconcileClientTrafficPolicy(ctx, r.Client, gatewayApiResource, annotations); err != nil {
+		log.Info("Update ClientTrafficPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+		errs = append(errs, fmt.Errorf("ClientTrafficPolicy: %w", err))
+	}
+
+	// Create, update or delete BackendTrafficPolicy based on annotations
+	if err := reconcileBackendTrafficPolicy(ctx, r.Client, gatewayApiResource, annotations); err != nil {
+		log.Info("Update BackendTrafficPolicy for Gateway", "Gateway.Namespace", req.Namespace, "Gateway.Name", req.Name, "Error", err)
+		errs = append(errs, fmt.Errorf("BackendTrafficPolicy: %w", err))
 	}
 
 	// Create a patch to update mandatory annotations
@@ -175,39 +242,32 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// Permanent errors are reported as events, only transient errors are retried
+	return ctrl.Result{}, handleReconcileErrors(r.Recorder, &gateway, errors.Join(errs...))
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
-	// Predicate that filters updates where only annotations changed
+	// Predicate that filters updates where only annotations changed. Spec changes
+	// are reconciled as well, since the listeners annotation refers to spec.listeners.
 	annotationChangedPredicate := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 
-			oldObjAnnotationSecurityPolicyDefaultAction := e.ObjectOld.GetAnnotations()[AnnotationSecurityPolicyDefaultAction]
-			newObjAnnotationSecurityPolicyDefaultAction := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyDefaultAction]
-
-			oldObjAnnotationSecurityPolicyLists := e.ObjectOld.GetAnnotations()[AnnotationSecurityPolicyLists]
-			newObjAnnotationSecurityPolicyLists := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyLists]
-
-			oldObjAnnotationSecurityPolicyAddresses := e.ObjectOld.GetAnnotations()[AnnotationSecurityPolicyAddresses]
-			newObjAnnotationSecurityPolicyAddresses := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyAddresses]
-
 			newdObjAnnotationSecurityPolicyLastUpdated := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyLastUpdated]
 
-			// Trigger reconciliation if relevant annotations have changed
-			return !reflect.DeepEqual(oldObjAnnotationSecurityPolicyDefaultAction, newObjAnnotationSecurityPolicyDefaultAction) ||
-				!reflect.DeepEqual(oldObjAnnotationSecurityPolicyLists, newObjAnnotationSecurityPolicyLists) ||
-				!reflect.DeepEqual(oldObjAnnotationSecurityPolicyAddresses, newObjAnnotationSecurityPolicyAddresses) ||
+			// Trigger reconciliation if relevant annotations or the spec have changed
+			return securityPolicyAnnotationsChanged(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()) ||
+				e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+				clientTrafficPolicyAnnotationsChanged(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()) ||
+				rateLimitAnnotationsChanged(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()) ||
+				e.ObjectOld.GetAnnotations()[AnnotationSecurityPolicyListeners] != e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyListeners] ||
 				newdObjAnnotationSecurityPolicyLastUpdated == "" ||
 				!reflect.DeepEqual(e.ObjectOld.GetDeletionTimestamp(), e.ObjectNew.GetDeletionTimestamp())
 		},
 		CreateFunc: func(e event.CreateEvent) bool {
 			// Trigger reconciliation if relevant annotations are present
-			return e.Object.GetAnnotations()[AnnotationSecurityPolicyDefaultAction] != "" ||
-				e.Object.GetAnnotations()[AnnotationSecurityPolicyLists] != "" ||
-				e.Object.GetAnnotations()[AnnotationSecurityPolicyAddresses] != ""
+			return hasGatewayAnnotations(e.Object.GetAnnotations())
 		},
 		GenericFunc: func(e event.GenericEvent) bool {
 			return false
@@ -223,3 +283,13 @@ func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		WithEventFilter(annotationChangedPredicate).
 		Complete(r)
 }
+
+// hasGatewayAnnotations reports whether any annotation configuring the SecurityPolicy,
+// the SecurityPolicies of the listeners, the ClientTrafficPolicy or the
+// BackendTrafficPolicy of a gateway is set.
+func hasGatewayAnnotations(annotations map[string]string) bool {
+	return hasSecurityPolicyAnnotations(annotations) ||
+		annotations[AnnotationSecurityPolicyListeners] != "" ||
+		hasClientTrafficPolicyAnnotations(annotations) ||
+		hasRateLimitAnnotations(annotations)
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestGatewayReconcileIndependentPolicies(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := envoyv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayv1.Install(scheme); err != nil {
+		t.Fatal(err)
+	}
+	gateway := &gatewayv1.Gateway{
+		TypeMeta: metav1.TypeMeta{APIVersion: gatewayv1.GroupVersion.String(), Kind: "Gateway"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "public",
+			Annotations: map[string]string{
+				// Invalid for the SecurityPolicy of the gateway and for the missing listener
+				AnnotationSecurityPolicyAddresses: "not-a-cidr",
+				AnnotationSecurityPolicyListeners: "admin:\n  addresses: 10.0.0.0/8\nmissing:\n  addresses: 10.0.0.0/8\n",
+				AnnotationSecurityPolicyRateLimit: "100/minute",
+			},
+		},
+		Spec: gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{{Name: "admin"}}},
+	}
+	r := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway).Build()
+	recorder := events.NewFakeRecorder(10)
+	reconciler := &GatewayReconciler{Client: r, Scheme: scheme, Recorder: recorder}
+
+	// Configuration errors are reported as events and not retried
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(gateway)}); err != nil {
+		t.Fatalf("expected no error to retry, got %v", err)
+	}
+
+	// The valid listener and the BackendTrafficPolicy are reconciled regardless
+	securityPolicyList := &envoyv1.SecurityPolicyList{}
+	if err := r.List(ctx, securityPolicyList); err != nil {
+		t.Fatal(err)
+	}
+	admin := false
+	for _, securityPolicy := range securityPolicyList.Items {
+		targetRefs := securityPolicy.Spec.TargetRefs
+		if len(targetRefs) == 1 && targetRefs[0].SectionName != nil && *targetRefs[0].SectionName == "admin" {
+			admin = securityPolicy.Spec.Authorization != nil && len(securityPolicy.Spec.Authorization.Rules) > 0
+		}
+	}
+	if !admin {
+		t.Errorf("expected SecurityPolicy with rules for the admin listener, got %+v", securityPolicyList.Items)
+	}
+	backendTrafficPolicyList := &envoyv1.BackendTrafficPolicyList{}
+	if err := r.List(ctx, backendTrafficPolicyList); err != nil {
+		t.Fatal(err)
+	}
+	if len(backendTrafficPolicyList.Items) != 1 {
+		t.Errorf("expected BackendTrafficPolicy of the gateway, got %+v", backendTrafficPolicyList.Items)
+	}
+
+	var reported []string
+	for len(recorder.Events) > 0 {
+		reported = append(reported, <-recorder.Events)
+	}
+	if len(reported) != 2 {
+		t.Fatalf("expected events for the gateway and the missing listener, got %q", reported)
+	}
+	for _, event := range reported {
+		if !strings.Contains(event, EventReasonInvalidConfiguration) {
+			t.Errorf("got event %q", event)
+		}
+	}
+}
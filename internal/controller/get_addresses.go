@@ -2,47 +2,177 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
-	v1 "k8s.io/api/networking/v1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func getAddresses(ctx context.Context, r Client, securityPolicyList []string, addressList []string) ([]string, error) {
+// addressSource holds the aggregated CIDRs of a single list, of the literal addresses
+// or of the set expression of a route or gateway. Name identifies the source in the
+// rules of the SecurityPolicy, e.g. list-expose-thula or addresses.
+type addressSource struct {
+	Name  string
+	CIDRs []string
+}
 
-	var cidrs []string
+// getAddresses returns the CIDRs of the given lists, addresses and set expression,
+// one source per list followed by the addresses and the expression. Sources are
+// named with namePrefix, duplicate lists are only returned once.
+// The CIDRs of dns: entries are taken from resolvedHosts, see resolveDNSHosts.
+func getAddresses(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, namePrefix string, securityPolicyList []string, addressList []string, expression string, resolvedHosts map[string][]string) ([]addressSource, error) {
+
+	var sources []addressSource
+	seen := make(map[string]struct{}, len(securityPolicyList))
 
-	// Get each NetworkPolicy and extract CIDRs
+	// Get each list and extract CIDRs
 
-	for _, networkPolicy := range securityPolicyList {
+	for _, list := range securityPolicyList {
 
-		processNetworkPolicy := v1.NetworkPolicy{}
+		name := listSourceName(namePrefix, list)
+		if _, exists := seen[name]; exists {
+			continue
+		}
+		seen[name] = struct{}{}
 
-		err := r.Get(ctx, client.ObjectKey{
-			Namespace: NetworkPoliciesNamespace,
-			Name:      networkPolicy,
-		}, &processNetworkPolicy)
+		reference, err := parseListReference(list, gatewayApiResource.Namespace)
+		if err != nil {
+			return nil, err
+		}
 
+		listCIDRs, err := resolveList(ctx, r, gatewayApiResource, reference)
 		if err != nil {
-			return nil, fmt.Errorf("unable to fetch NetworkPolicy %q: %w", networkPolicy, err)
+			return nil, err
 		}
 
-		// Extract CIDRs from NetworkPolicy and append to list
-		cidrs = append(cidrs, extractCIDRsFromNetworkPolicy(&processNetworkPolicy, cidrs)...)
+		// Append CIDRs from list
+		source, err := newAddressSource(name, listCIDRs)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
 	}
 
 	// Append valid CIDRs from customList
+	var cidrs []string
 	for _, cidr := range addressList {
-		if utils.CheckValidCIDR(cidr) {
+		token, isToken, err := parseAddressToken(cidr, gatewayApiResource.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if isToken {
+			tokenCIDRs, err := resolveAddressToken(ctx, r, gatewayApiResource, token)
+			if err != nil {
+				return nil, err
+			}
+			cidrs = append(cidrs, tokenCIDRs...)
+			continue
+		}
+
+		host, isDNSEntry, err := parseDNSEntry(cidr)
+		if err != nil {
+			return nil, err
+		}
+		if isDNSEntry {
+			// Append resolved CIDRs from DNS entries
+			cidrs = append(cidrs, resolvedHosts[host]...)
+		} else if utils.CheckValidCIDR(cidr) {
 			cidrs = append(cidrs, cidr)
 		} else {
 			return nil, fmt.Errorf("Invalid CIDR %q in custom list, skipping", cidr)
 		}
 	}
+	if len(addressList) > 0 {
+		source, err := newAddressSource(namePrefix+RuleNameAddresses, cidrs)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	// Append CIDRs from the set expression
+	if strings.TrimSpace(expression) != "" {
+		expressionCIDRs, err := evaluateExpression(ctx, r, gatewayApiResource, expression)
+		if err != nil {
+			return nil, err
+		}
+		source, err := newAddressSource(namePrefix+RuleNameExpression, expressionCIDRs)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+// listSourceName returns the name of the source of a list. Names longer than an
+// AuthorizationRule name can be are truncated and suffixed with a hash of the full
+// name, so long references to different lists keep distinct names.
+func listSourceName(namePrefix string, list string) string {
+	name := namePrefix + RuleNameListPrefix + list
+	if len(name) <= RuleNameMaxLength {
+		return name
+	}
+	hash := sha256.Sum256([]byte(name))
+	suffix := "-" + hex.EncodeToString(hash[:4])
+	return name[:RuleNameMaxLength-len(suffix)] + suffix
+}
+
+// isGeneratedRuleName reports whether name has the form of the rule names generated
+// for the sources of the shorthand annotations, e.g. list-office or deny-addresses.
+func isGeneratedRuleName(name string) bool {
+	if trimmed, found := strings.CutPrefix(name, "allow-"); found {
+		name = trimmed
+	} else {
+		name = strings.TrimPrefix(name, "deny-")
+	}
+	return name == RuleNameAddresses || name == RuleNameExpression || strings.HasPrefix(name, RuleNameListPrefix)
+}
+
+// newAddressSource aggregates the CIDRs into a minimal, numerically sorted list.
+func newAddressSource(name string, cidrs []string) (addressSource, error) {
+	prefixSet, err := utils.NewPrefixSet(cidrs...)
+	if err != nil {
+		return addressSource{}, err
+	}
+	return addressSource{Name: name, CIDRs: prefixSet.Strings()}, nil
+}
+
+// resolveDNSHosts resolves the dns: entries of all address lists of a resource at once,
+// so the DNS resolver tracks every host the resource uses. Hosts new to the resolver
+// start from the CIDRs in previous.
+func resolveDNSHosts(ctx context.Context, dnsResolver *DNSResolver, gatewayApiResource gatewayApiResource, previous map[string][]string, addressLists ...[]string) (map[string][]string, error) {
 
-	// Remove duplicates and sort
-	compactSortedCIDRs := utils.SortSlice(cidrs)
+	var hosts []string
+	for _, addressList := range addressLists {
+		for _, entry := range addressList {
+			host, isDNSEntry, err := parseDNSEntry(entry)
+			if err != nil {
+				return nil, err
+			}
+			if isDNSEntry {
+				hosts = append(hosts, host)
+			}
+		}
+	}
 
-	return compactSortedCIDRs, nil
+	if dnsResolver == nil {
+		if len(hosts) > 0 {
+			return nil, fmt.Errorf("DNS entries are not supported for %s %s/%s", gatewayApiResource.Kind, gatewayApiResource.Namespace, gatewayApiResource.Name)
+		}
+		return nil, nil
+	}
+
+	return dnsResolver.Resolve(ctx, gatewayApiResource, hosts, previous), nil
+}
+
+// forgetDNSHosts stops resolving the dns: entries of a resource that is deleted or no
+// longer has a SecurityPolicy.
+func forgetDNSHosts(ctx context.Context, dnsResolver *DNSResolver, gatewayApiResource gatewayApiResource) {
+	if dnsResolver != nil {
+		dnsResolver.Resolve(ctx, gatewayApiResource, nil, nil)
+	}
 }
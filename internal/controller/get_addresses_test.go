@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListSourceName(t *testing.T) {
+	if got := listSourceName("deny-", "configmap:team-a/partners"); got != "deny-list-configmap:team-a/partners" {
+		t.Errorf("got %q", got)
+	}
+
+	// References of the maximum length exceed the name of an AuthorizationRule
+	long := "configmap:" + strings.Repeat("n", 63) + "/" + strings.Repeat("a", 253)
+	other := "configmap:" + strings.Repeat("n", 63) + "/" + strings.Repeat("a", 252) + "b"
+	name, otherName := listSourceName("deny-", long), listSourceName("deny-", other)
+	if len(name) != RuleNameMaxLength || !strings.HasPrefix(name, "deny-list-configmap:") {
+		t.Errorf("got %q with length %d", name, len(name))
+	}
+	if name == otherName {
+		t.Errorf("expected distinct names for different lists, got %q", name)
+	}
+	if !isGeneratedRuleName(name) {
+		t.Errorf("expected %q to be reserved", name)
+	}
+}
+
+func TestIsGeneratedRuleName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"list-office":       true,
+		"addresses":         true,
+		"expression":        true,
+		"allow-addresses":   true,
+		"deny-list-vpn":     true,
+		"office-write":      false,
+		"allow-office":      false,
+		"addresses-partner": false,
+		"deny-":             false,
+		"allow-deny-list-x": false,
+	} {
+		if got := isGeneratedRuleName(name); got != want {
+			t.Errorf("isGeneratedRuleName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
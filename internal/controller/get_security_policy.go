@@ -22,8 +22,7 @@ func getSecurityPolicy(ctx context.Context, r client.Client, gatewayApiResource
 	if len(securityPolicyList.Items) > 0 {
 		for _, securityPolicy := range securityPolicyList.Items {
 			for _, targetRef := range securityPolicy.Spec.TargetRefs {
-				if string(targetRef.Name) == gatewayApiResource.Name &&
-					string(targetRef.Kind) == gatewayApiResource.Kind {
+				if gatewayApiResource.targetedBy(targetRef) {
 					processedSecurityPolicyList = append(processedSecurityPolicyList, securityPolicy)
 				}
 			}
@@ -18,10 +18,13 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,12 +39,21 @@ import (
 // GRPCRouteReconciler reconciles a grpcroute object
 type GRPCRouteReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme      *runtime.Scheme
+	DNSResolver *DNSResolver
+	Recorder    events.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=grpcroutes,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=securitypolicies,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=backendtrafficpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes;services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
+// +kubebuilder:rbac:groups=securitypolicies.vitistack.io,resources=addresslists,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -77,9 +89,7 @@ func (r *GRPCRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		// then let's add the finalizer and update the object. This is equivalent
 		// to registering our finalizer.
 		if !controllerutil.ContainsFinalizer(&grpcroute, FinalizerSecurityPolicy) &&
-			(grpcroute.Annotations[AnnotationSecurityPolicyDefaultAction] != "" ||
-				grpcroute.Annotations[AnnotationSecurityPolicyLists] != "" ||
-				grpcroute.Annotations[AnnotationSecurityPolicyAddresses] != "") {
+			(hasSecurityPolicyAnnotations(grpcroute.Annotations) || hasRateLimitAnnotations(grpcroute.Annotations)) {
 			log.Info("Add Finalizer", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name)
 			controllerutil.AddFinalizer(&grpcroute, FinalizerSecurityPolicy)
 			if err := r.Update(ctx, &grpcroute); err != nil {
@@ -97,6 +107,11 @@ func (r *GRPCRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 				return ctrl.Result{}, err
 			}
 		}
+		if err := deleteBackendTrafficPolicy(ctx, r.Client, gatewayApiResource); err != nil {
+			log.Info("Failed to delete BackendTrafficPolicy", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name, "Error", err)
+			return ctrl.Result{}, err
+		}
+		forgetDNSHosts(ctx, r.DNSResolver, gatewayApiResource)
 		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			var latest gatewayv1.GRPCRoute
 			// Get the latest version of the HTTPRoute object.
@@ -125,9 +140,9 @@ func (r *GRPCRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	// Delete SecurityPolicy if relevant annotations are removed from GRPCRoute
-	if grpcroute.Annotations[AnnotationSecurityPolicyDefaultAction] == "" &&
-		grpcroute.Annotations[AnnotationSecurityPolicyLists] == "" &&
-		grpcroute.Annotations[AnnotationSecurityPolicyAddresses] == "" {
+	if !hasSecurityPolicyAnnotations(grpcroute.Annotations) {
+		forgetDNSHosts(ctx, r.DNSResolver, gatewayApiResource)
+
 		// Only delete if a SecurityPolicy actually exists
 		if _, err := getSecurityPolicy(ctx, r.Client, gatewayApiResource); err == nil {
 			log.Info("Relevant annotations removed from GRPCRoute, deleting associated SecurityPolicy", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name)
@@ -137,6 +152,14 @@ func (r *GRPCRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			}
 			log.Info("Deleted SecurityPolicy for GRPCRoute", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name)
 		}
+	}
+
+	// Delete BackendTrafficPolicy and stop if no relevant annotations are left on GRPCRoute
+	if !hasSecurityPolicyAnnotations(grpcroute.Annotations) && !hasRateLimitAnnotations(grpcroute.Annotations) {
+		if err := deleteBackendTrafficPolicy(ctx, r.Client, gatewayApiResource); err != nil {
+			log.Info("Failed to delete BackendTrafficPolicy", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name, "Error", err)
+			return ctrl.Result{}, err
+		}
 
 		// Remove our finalizer and the annotations we manage.
 		// Only patch when there is something to remove, otherwise the resulting
@@ -158,29 +181,44 @@ func (r *GRPCRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
-	// Get SecurityPolicy associated with this grpcroute
-	securityPolicy, err := getSecurityPolicy(ctx, r.Client, gatewayApiResource)
-	if err != nil {
-		log.Info("Unable to fetch SecurityPolicy for GRPCRoute", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name, "Error", err)
-	}
+	// Get annotations from GRPCRoute
+	annotations := grpcroute.Annotations
 
-	// Create SecurityPolicy if it does not exist
-	if securityPolicy.Name == "" {
-		securityPolicy, err = createSecurityPolicy(ctx, r.Client, gatewayApiResource)
+	// The SecurityPolicy and the BackendTrafficPolicy are reconciled independently,
+	// an error in one of them does not hold back the other.
+	var errs []error
+
+	if hasSecurityPolicyAnnotations(annotations) {
+		// Get SecurityPolicy associated with this grpcroute
+		securityPolicy, err := getSecurityPolicy(ctx, r.Client, gatewayApiResource)
 		if err != nil {
-			log.Info("Unable to create SecurityPolicy for GRPCRoute", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name, "Error", err)
-			return ctrl.Result{}, err
+			log.Info("Unable to fetch SecurityPolicy for GRPCRoute", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name, "Error", err)
 		}
-		log.Info("Created SecurityPolicy for GRPCRoute", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name)
-	}
 
-	// Get annotations from GRPCRoute
-	annotations := grpcroute.Annotations
+		// Create SecurityPolicy if it does not exist
+		if securityPolicy.Name == "" {
+			securityPolicy, err = createSecurityPolicy(ctx, r.Client, gatewayApiResource)
+			if err != nil {
+				log.Info("Unable to create SecurityPolicy for GRPCRoute", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name, "Error", err)
+				errs = append(errs, err)
+			} else {
+				log.Info("Created SecurityPolicy for GRPCRoute", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name)
+			}
+		}
 
-	// Update SecurityPolicy based on annotations
-	if err := updateSecurityPolicy(ctx, r.Client, securityPolicy, annotations); err != nil {
-		log.Info("Update SecurityPolicy for GRPCRoute", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name, "Error", err)
-		return ctrl.Result{}, nil
+		// Update SecurityPolicy based on annotations
+		if securityPolicy.Name != "" {
+			if err := updateSecurityPolicy(ctx, r.Client, r.DNSResolver, gatewayApiResource, securityPolicy, annotations); err != nil {
+				log.Info("Update SecurityPolicy for GRPCRoute", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name, "Error", err)
+				errs = append(errs, fmt.Errorf("SecurityPolicy: %w", err))
+			}
+		}
+	}
+
+	// Create, update or delete BackendTrafficPolicy based on annotations
+	if err := reconcileBackendTrafficPolicy(ctx, r.Client, gatewayApiResource, annotations); err != nil {
+		log.Info("Update BackendTrafficPolicy for GRPCRoute", "GRPCRoute.Namespace", req.Namespace, "GRPCRoute.Name", req.Name, "Error", err)
+		errs = append(errs, fmt.Errorf("BackendTrafficPolicy: %w", err))
 	}
 
 	// Create a patch to update mandatory annotations
@@ -194,7 +232,8 @@ func (r *GRPCRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// Permanent errors are reported as events, only transient errors are retried
+	return ctrl.Result{}, handleReconcileErrors(r.Recorder, &grpcroute, errors.Join(errs...))
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -204,29 +243,18 @@ func (r *GRPCRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	annotationChangedPredicate := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 
-			oldObjAnnotationSecurityPolicyDefaultAction := e.ObjectOld.GetAnnotations()[AnnotationSecurityPolicyDefaultAction]
-			newObjAnnotationSecurityPolicyDefaultAction := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyDefaultAction]
-
-			oldObjAnnotationSecurityPolicyLists := e.ObjectOld.GetAnnotations()[AnnotationSecurityPolicyLists]
-			newObjAnnotationSecurityPolicyLists := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyLists]
-
-			oldObjAnnotationSecurityPolicyAddresses := e.ObjectOld.GetAnnotations()[AnnotationSecurityPolicyAddresses]
-			newObjAnnotationSecurityPolicyAddresses := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyAddresses]
-
 			newdObjAnnotationSecurityPolicyLastUpdated := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyLastUpdated]
 
 			// Trigger reconciliation if relevant annotations have changed
-			return !reflect.DeepEqual(oldObjAnnotationSecurityPolicyDefaultAction, newObjAnnotationSecurityPolicyDefaultAction) ||
-				!reflect.DeepEqual(oldObjAnnotationSecurityPolicyLists, newObjAnnotationSecurityPolicyLists) ||
-				!reflect.DeepEqual(oldObjAnnotationSecurityPolicyAddresses, newObjAnnotationSecurityPolicyAddresses) ||
+			return securityPolicyAnnotationsChanged(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()) ||
+				rateLimitAnnotationsChanged(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()) ||
 				newdObjAnnotationSecurityPolicyLastUpdated == "" ||
 				!reflect.DeepEqual(e.ObjectOld.GetDeletionTimestamp(), e.ObjectNew.GetDeletionTimestamp())
 		},
 		CreateFunc: func(e event.CreateEvent) bool {
 			// Trigger reconciliation if relevant annotations are present
-			return e.Object.GetAnnotations()[AnnotationSecurityPolicyDefaultAction] != "" ||
-				e.Object.GetAnnotations()[AnnotationSecurityPolicyLists] != "" ||
-				e.Object.GetAnnotations()[AnnotationSecurityPolicyAddresses] != ""
+			return hasSecurityPolicyAnnotations(e.Object.GetAnnotations()) ||
+				hasRateLimitAnnotations(e.Object.GetAnnotations())
 		},
 		GenericFunc: func(e event.GenericEvent) bool {
 			return false
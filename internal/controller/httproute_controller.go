@@ -18,10 +18,13 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,12 +39,21 @@ import (
 // HTTPRouteReconciler reconciles a HTTPRoute object
 type HTTPRouteReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme      *runtime.Scheme
+	DNSResolver *DNSResolver
+	Recorder    events.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=securitypolicies,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=backendtrafficpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes;services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
+// +kubebuilder:rbac:groups=securitypolicies.vitistack.io,resources=addresslists,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -77,9 +89,7 @@ func (r *HTTPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		// then let's add the finalizer and update the object. This is equivalent
 		// to registering our finalizer.
 		if !controllerutil.ContainsFinalizer(&httproute, FinalizerSecurityPolicy) &&
-			(httproute.Annotations[AnnotationSecurityPolicyDefaultAction] != "" ||
-				httproute.Annotations[AnnotationSecurityPolicyLists] != "" ||
-				httproute.Annotations[AnnotationSecurityPolicyAddresses] != "") {
+			(hasSecurityPolicyAnnotations(httproute.Annotations) || hasRateLimitAnnotations(httproute.Annotations)) {
 			log.Info("Add Finalizer", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name)
 			controllerutil.AddFinalizer(&httproute, FinalizerSecurityPolicy)
 			if err := r.Update(ctx, &httproute); err != nil {
@@ -97,6 +107,11 @@ func (r *HTTPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 				return ctrl.Result{}, err
 			}
 		}
+		if err := deleteBackendTrafficPolicy(ctx, r.Client, gatewayApiResource); err != nil {
+			log.Info("Failed to delete BackendTrafficPolicy", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name, "Error", err)
+			return ctrl.Result{}, err
+		}
+		forgetDNSHosts(ctx, r.DNSResolver, gatewayApiResource)
 		// remove our finalizer from the list and update it.
 		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			var latest gatewayv1.HTTPRoute
@@ -128,9 +143,9 @@ func (r *HTTPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	// Delete SecurityPolicy if relevant annotations are removed from HTTPRoute
-	if httproute.Annotations[AnnotationSecurityPolicyDefaultAction] == "" &&
-		httproute.Annotations[AnnotationSecurityPolicyLists] == "" &&
-		httproute.Annotations[AnnotationSecurityPolicyAddresses] == "" {
+	if !hasSecurityPolicyAnnotations(httproute.Annotations) {
+		forgetDNSHosts(ctx, r.DNSResolver, gatewayApiResource)
+
 		// Only delete if a SecurityPolicy actually exists
 		if _, err := getSecurityPolicy(ctx, r.Client, gatewayApiResource); err == nil {
 			log.Info("Relevant annotations removed from HTTPRoute, deleting associated SecurityPolicy", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name)
@@ -140,6 +155,14 @@ func (r *HTTPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			}
 			log.Info("Deleted SecurityPolicy for HTTPRoute", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name)
 		}
+	}
+
+	// Delete BackendTrafficPolicy and stop if no relevant annotations are left on HTTPRoute
+	if !hasSecurityPolicyAnnotations(httproute.Annotations) && !hasRateLimitAnnotations(httproute.Annotations) {
+		if err := deleteBackendTrafficPolicy(ctx, r.Client, gatewayApiResource); err != nil {
+			log.Info("Failed to delete BackendTrafficPolicy", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name, "Error", err)
+			return ctrl.Result{}, err
+		}
 
 		// Remove our finalizer and the annotations we manage.
 		// Only patch when there is something to remove, otherwise the resulting
@@ -161,29 +184,44 @@ func (r *HTTPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
-	// Get SecurityPolicy associated with this HTTPRoute
-	securityPolicy, err := getSecurityPolicy(ctx, r.Client, gatewayApiResource)
-	if err != nil {
-		log.Info("Unable to fetch SecurityPolicy for HTTPRoute", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name, "Error", err)
-	}
+	// Get annotations from HTTPRoute
+	annotations := httproute.Annotations
 
-	// Create SecurityPolicy if it does not exist
-	if securityPolicy.Name == "" {
-		securityPolicy, err = createSecurityPolicy(ctx, r.Client, gatewayApiResource)
+	// The SecurityPolicy and the BackendTrafficPolicy are reconciled independently,
+	// an error in one of them does not hold back the other.
+	var errs []error
+
+	if hasSecurityPolicyAnnotations(annotations) {
+		// Get SecurityPolicy associated with this HTTPRoute
+		securityPolicy, err := getSecurityPolicy(ctx, r.Client, gatewayApiResource)
 		if err != nil {
-			log.Info("Unable to create SecurityPolicy for HTTPRoute", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name, "Error", err)
-			return ctrl.Result{}, err
+			log.Info("Unable to fetch SecurityPolicy for HTTPRoute", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name, "Error", err)
 		}
-		log.Info("Created SecurityPolicy for HTTPRoute", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name)
-	}
 
-	// Get annotations from HTTPRoute
-	annotations := httproute.Annotations
+		// Create SecurityPolicy if it does not exist
+		if securityPolicy.Name == "" {
+			securityPolicy, err = createSecurityPolicy(ctx, r.Client, gatewayApiResource)
+			if err != nil {
+				log.Info("Unable to create SecurityPolicy for HTTPRoute", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name, "Error", err)
+				errs = append(errs, err)
+			} else {
+				log.Info("Created SecurityPolicy for HTTPRoute", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name)
+			}
+		}
 
-	// Update SecurityPolicy based on annotations
-	if err := updateSecurityPolicy(ctx, r.Client, securityPolicy, annotations); err != nil {
-		log.Info("Reconciling HttpRoute failed!", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name, "Error", err)
-		return ctrl.Result{}, nil
+		// Update SecurityPolicy based on annotations
+		if securityPolicy.Name != "" {
+			if err := updateSecurityPolicy(ctx, r.Client, r.DNSResolver, gatewayApiResource, securityPolicy, annotations); err != nil {
+				log.Info("Reconciling HttpRoute failed!", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name, "Error", err)
+				errs = append(errs, fmt.Errorf("SecurityPolicy: %w", err))
+			}
+		}
+	}
+
+	// Create, update or delete BackendTrafficPolicy based on annotations
+	if err := reconcileBackendTrafficPolicy(ctx, r.Client, gatewayApiResource, annotations); err != nil {
+		log.Info("Update BackendTrafficPolicy for HTTPRoute", "HttpRoute.Namespace", req.Namespace, "HttpRoute.Name", req.Name, "Error", err)
+		errs = append(errs, fmt.Errorf("BackendTrafficPolicy: %w", err))
 	}
 
 	// Create a patch to update mandatory annotations
@@ -197,7 +235,8 @@ func (r *HTTPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// Permanent errors are reported as events, only transient errors are retried
+	return ctrl.Result{}, handleReconcileErrors(r.Recorder, &httproute, errors.Join(errs...))
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -207,29 +246,18 @@ func (r *HTTPRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	annotationChangedPredicate := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 
-			oldObjAnnotationSecurityPolicyDefaultAction := e.ObjectOld.GetAnnotations()[AnnotationSecurityPolicyDefaultAction]
-			newObjAnnotationSecurityPolicyDefaultAction := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyDefaultAction]
-
-			oldObjAnnotationSecurityPolicyLists := e.ObjectOld.GetAnnotations()[AnnotationSecurityPolicyLists]
-			newObjAnnotationSecurityPolicyLists := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyLists]
-
-			oldObjAnnotationSecurityPolicyAddresses := e.ObjectOld.GetAnnotations()[AnnotationSecurityPolicyAddresses]
-			newObjAnnotationSecurityPolicyAddresses := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyAddresses]
-
 			newdObjAnnotationSecurityPolicyLastUpdated := e.ObjectNew.GetAnnotations()[AnnotationSecurityPolicyLastUpdated]
 
 			// Trigger reconciliation if relevant annotations have changed
-			return !reflect.DeepEqual(oldObjAnnotationSecurityPolicyDefaultAction, newObjAnnotationSecurityPolicyDefaultAction) ||
-				!reflect.DeepEqual(oldObjAnnotationSecurityPolicyLists, newObjAnnotationSecurityPolicyLists) ||
-				!reflect.DeepEqual(oldObjAnnotationSecurityPolicyAddresses, newObjAnnotationSecurityPolicyAddresses) ||
+			return securityPolicyAnnotationsChanged(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()) ||
+				rateLimitAnnotationsChanged(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()) ||
 				newdObjAnnotationSecurityPolicyLastUpdated == "" ||
 				!reflect.DeepEqual(e.ObjectOld.GetDeletionTimestamp(), e.ObjectNew.GetDeletionTimestamp())
 		},
 		CreateFunc: func(e event.CreateEvent) bool {
 			// Trigger reconciliation if relevant annotations are present
-			return e.Object.GetAnnotations()[AnnotationSecurityPolicyDefaultAction] != "" ||
-				e.Object.GetAnnotations()[AnnotationSecurityPolicyLists] != "" ||
-				e.Object.GetAnnotations()[AnnotationSecurityPolicyAddresses] != ""
+			return hasSecurityPolicyAnnotations(e.Object.GetAnnotations()) ||
+				hasRateLimitAnnotations(e.Object.GetAnnotations())
 		},
 		GenericFunc: func(e event.GenericEvent) bool {
 			return false
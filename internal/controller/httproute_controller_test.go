@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestHTTPRouteReconcileIndependentPolicies(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := envoyv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayv1.Install(scheme); err != nil {
+		t.Fatal(err)
+	}
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "api",
+			Annotations: map[string]string{
+				// The invalid origin must not hold back the rate limit
+				AnnotationSecurityPolicyCORSAllowOrigins: "app.example.com",
+				AnnotationSecurityPolicyRateLimit:        "100/minute",
+			},
+		},
+	}
+	r := fake.NewClientBuilder().WithScheme(scheme).WithObjects(route).Build()
+	recorder := events.NewFakeRecorder(10)
+	reconciler := &HTTPRouteReconciler{Client: r, Scheme: scheme, Recorder: recorder}
+
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(route)}); err != nil {
+		t.Fatalf("expected no error to retry, got %v", err)
+	}
+
+	backendTrafficPolicyList := &envoyv1.BackendTrafficPolicyList{}
+	if err := r.List(ctx, backendTrafficPolicyList); err != nil {
+		t.Fatal(err)
+	}
+	if len(backendTrafficPolicyList.Items) != 1 || backendTrafficPolicyList.Items[0].Spec.RateLimit == nil {
+		t.Errorf("expected the BackendTrafficPolicy with the rate limit, got %+v", backendTrafficPolicyList.Items)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, EventReasonInvalidConfiguration) || !strings.Contains(event, "SecurityPolicy") {
+			t.Errorf("got event %q", event)
+		}
+	default:
+		t.Error("expected an event for the invalid CORS origin")
+	}
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+)
+
+// jwtAnnotations are the annotations that configure the JWT provider.
+var jwtAnnotations = []string{
+	AnnotationSecurityPolicyJWTIssuer,
+	AnnotationSecurityPolicyJWTJWKSURI,
+	AnnotationSecurityPolicyJWTAudiences,
+	AnnotationSecurityPolicyJWTOptional,
+}
+
+// jwtProvider returns the JWT authentication of the SecurityPolicy described by the
+// jwt- annotations, or nil if no JWT provider is configured. The provider is
+// named JWTProviderName, so rules can match on its claims and scopes.
+func jwtProvider(annotations map[string]string) (*envoyv1.JWT, error) {
+	configured := slices.ContainsFunc(jwtAnnotations, func(annotation string) bool {
+		return annotations[annotation] != ""
+	})
+	if !configured {
+		return nil, nil
+	}
+
+	jwksURI := annotations[AnnotationSecurityPolicyJWTJWKSURI]
+	if jwksURI == "" {
+		return nil, fmt.Errorf("%s is required to configure JWT authentication", AnnotationSecurityPolicyJWTJWKSURI)
+	}
+	if parsed, err := url.Parse(jwksURI); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return nil, fmt.Errorf("%s not valid: %q. Expected an http or https URL", AnnotationSecurityPolicyJWTJWKSURI, jwksURI)
+	}
+
+	provider := envoyv1.JWTProvider{
+		Name:       JWTProviderName,
+		Issuer:     annotations[AnnotationSecurityPolicyJWTIssuer],
+		Audiences:  annotationEntries(annotations, AnnotationSecurityPolicyJWTAudiences),
+		RemoteJWKS: &envoyv1.RemoteJWKS{URI: jwksURI},
+	}
+
+	jwt := &envoyv1.JWT{Providers: []envoyv1.JWTProvider{provider}}
+	if value, ok := annotations[AnnotationSecurityPolicyJWTOptional]; ok {
+		optional, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s not valid: %q. Valid values: true || false", AnnotationSecurityPolicyJWTOptional, value)
+		}
+		jwt.Optional = &optional
+	}
+
+	return jwt, nil
+}
+
+// parseJWTPrincipalAnnotations returns the JWT principal described by the jwt-claims
+// and jwt-scopes annotations, or nil if neither is set. Claims are comma separated
+// "name=value" entries, entries with the same claim name are combined and any of
+// their values matches. A name ending in "[]" matches a claim holding a list of strings.
+func parseJWTPrincipalAnnotations(annotations map[string]string) (*envoyv1.JWTPrincipal, error) {
+	var claims []envoyv1.JWTClaim
+	for _, entry := range annotationEntries(annotations, AnnotationSecurityPolicyJWTClaims) {
+		name, value, found := strings.Cut(entry, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		valueType := envoyv1.JWTClaimValueTypeString
+		if arrayName, isArray := strings.CutSuffix(name, "[]"); isArray {
+			name, valueType = arrayName, envoyv1.JWTClaimValueTypeStringArray
+		}
+		if !found || name == "" || value == "" {
+			return nil, fmt.Errorf("%s not valid: %q. Expected name=value", AnnotationSecurityPolicyJWTClaims, entry)
+		}
+
+		index := slices.IndexFunc(claims, func(claim envoyv1.JWTClaim) bool {
+			return claim.Name == name && *claim.ValueType == valueType
+		})
+		if index < 0 {
+			claims = append(claims, envoyv1.JWTClaim{Name: name, ValueType: &valueType})
+			index = len(claims) - 1
+		}
+		if !slices.Contains(claims[index].Values, value) {
+			claims[index].Values = append(claims[index].Values, value)
+		}
+	}
+
+	var scopes []envoyv1.JWTScope
+	for _, scope := range annotationEntries(annotations, AnnotationSecurityPolicyJWTScopes) {
+		scopes = append(scopes, envoyv1.JWTScope(scope))
+	}
+
+	if len(claims) == 0 && len(scopes) == 0 {
+		return nil, nil
+	}
+
+	return &envoyv1.JWTPrincipal{Provider: JWTProviderName, Claims: claims, Scopes: scopes}, nil
+}
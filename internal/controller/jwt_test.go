@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"testing"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+)
+
+func TestJWTProvider(t *testing.T) {
+	if jwt, err := jwtProvider(map[string]string{}); jwt != nil || err != nil {
+		t.Errorf("got %+v, %v, want no provider", jwt, err)
+	}
+
+	jwt, err := jwtProvider(map[string]string{
+		AnnotationSecurityPolicyJWTIssuer:    "https://idp.example.com",
+		AnnotationSecurityPolicyJWTJWKSURI:   "https://idp.example.com/jwks.json",
+		AnnotationSecurityPolicyJWTAudiences: "api, console",
+		AnnotationSecurityPolicyJWTOptional:  "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := jwt.Providers[0]
+	if provider.Name != JWTProviderName || provider.RemoteJWKS.URI != "https://idp.example.com/jwks.json" || len(provider.Audiences) != 2 || !*jwt.Optional {
+		t.Errorf("got %+v", jwt)
+	}
+
+	for _, annotations := range []map[string]string{
+		{AnnotationSecurityPolicyJWTIssuer: "https://idp.example.com"},
+		{AnnotationSecurityPolicyJWTJWKSURI: "idp.example.com/jwks.json"},
+		{AnnotationSecurityPolicyJWTJWKSURI: "https://idp.example.com/jwks.json", AnnotationSecurityPolicyJWTOptional: "maybe"},
+	} {
+		if _, err := jwtProvider(annotations); err == nil {
+			t.Errorf("expected error for %v", annotations)
+		}
+	}
+}
+
+func TestParseJWTPrincipalAnnotations(t *testing.T) {
+	principal, err := parseJWTPrincipalAnnotations(map[string]string{
+		AnnotationSecurityPolicyJWTClaims: "org=vitistack, groups[]=admins, groups[]=operators",
+		AnnotationSecurityPolicyJWTScopes: "read,write",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(principal.Claims) != 2 || len(principal.Scopes) != 2 {
+		t.Fatalf("got %+v, want 2 claims and 2 scopes", principal)
+	}
+	if claim := principal.Claims[1]; claim.Name != "groups" || *claim.ValueType != envoyv1.JWTClaimValueTypeStringArray || len(claim.Values) != 2 {
+		t.Errorf("got %+v, want groups as StringArray with 2 values", claim)
+	}
+
+	if _, err := parseJWTPrincipalAnnotations(map[string]string{AnnotationSecurityPolicyJWTClaims: "org"}); err == nil {
+		t.Error("expected error for claim without value")
+	}
+}
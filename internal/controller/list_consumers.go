@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// listConsumer is a route or gateway that references a list.
+type listConsumer struct {
+	gatewayApiResource
+	Object client.Object
+}
+
+// listConsumers returns all HTTPRoutes, GRPCRoutes and Gateways in the cluster whose
+// list or expression annotations reference the target list.
+func listConsumers(ctx context.Context, r Client, target listReference) ([]listConsumer, error) {
+	return findConsumers(ctx, r, func(annotations map[string]string, namespace string) bool {
+		return referencesList(listEntries(annotations, namespace), namespace, target)
+	})
+}
+
+// addressTokenConsumers returns all HTTPRoutes, GRPCRoutes and Gateways in the cluster
+// whose address or expression annotations contain one of the target tokens.
+func addressTokenConsumers(ctx context.Context, r Client, targets ...addressToken) ([]listConsumer, error) {
+	return findConsumers(ctx, r, func(annotations map[string]string, namespace string) bool {
+		return referencesAddressToken(addressEntries(annotations, namespace), namespace, targets...)
+	})
+}
+
+// referenceGrantConsumers returns all HTTPRoutes, GRPCRoutes and Gateways outside of
+// namespace that reference a list or Service in namespace, and so depend on the
+// ReferenceGrants in namespace. Lists in NetworkPoliciesNamespace never require a grant.
+func referenceGrantConsumers(ctx context.Context, r Client, namespace string) ([]listConsumer, error) {
+	if namespace == NetworkPoliciesNamespace {
+		return nil, nil
+	}
+	return findConsumers(ctx, r, func(annotations map[string]string, consumerNamespace string) bool {
+		if consumerNamespace == namespace {
+			return false
+		}
+		for _, entry := range listEntries(annotations, consumerNamespace) {
+			if reference, err := parseListReference(entry, consumerNamespace); err == nil && reference.Namespace == namespace {
+				return true
+			}
+		}
+		for _, entry := range addressEntries(annotations, consumerNamespace) {
+			if token, isToken, err := parseAddressToken(entry, consumerNamespace); err == nil && isToken &&
+				token.Name == AddressTokenService && token.Service.Namespace == namespace {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// configMapConsumers returns all HTTPRoutes, GRPCRoutes and Gateways in the cluster
+// whose list or expression annotations reference the ConfigMap.
+func configMapConsumers(ctx context.Context, r Client, namespace string, name string) ([]listConsumer, error) {
+	return listConsumers(ctx, r, listReference{Kind: ConfigMapKind, Namespace: namespace, Name: name})
+}
+
+// secretConsumers returns all HTTPRoutes, GRPCRoutes and Gateways in the namespace
+// of the Secret that reference it in one of their Secret annotations.
+func secretConsumers(ctx context.Context, r Client, namespace string, name string) ([]listConsumer, error) {
+	return localReferenceConsumers(ctx, r, namespace, name, secretAnnotations)
+}
+
+// serviceConsumers returns all HTTPRoutes, GRPCRoutes and Gateways in the namespace
+// of the Service that reference it in one of their Service annotations.
+func serviceConsumers(ctx context.Context, r Client, namespace string, name string) ([]listConsumer, error) {
+	return localReferenceConsumers(ctx, r, namespace, name, serviceAnnotations)
+}
+
+// localReferenceConsumers returns all HTTPRoutes, GRPCRoutes and Gateways in namespace
+// where one of the given annotations, or those of a listener, contains name.
+func localReferenceConsumers(ctx context.Context, r Client, namespace string, name string, referenceAnnotations []string) ([]listConsumer, error) {
+	return findConsumers(ctx, r, func(annotations map[string]string, consumerNamespace string) bool {
+		return consumerNamespace == namespace &&
+			slices.ContainsFunc(annotationSets(annotations), func(set map[string]string) bool {
+				return slices.Contains(annotationEntries(set, referenceAnnotations...), name)
+			})
+	})
+}
+
+// findConsumers returns all HTTPRoutes, GRPCRoutes and Gateways in the cluster whose
+// annotations match.
+func findConsumers(ctx context.Context, r Client, matches func(annotations map[string]string, namespace string) bool) ([]listConsumer, error) {
+
+	var consumers []listConsumer
+
+	// Fetch all HttpRoutes in the cluster
+	var httpRouteList gatewayv1.HTTPRouteList
+	if err := r.List(ctx, &httpRouteList); err != nil {
+		return nil, err
+	}
+	for i := range httpRouteList.Items {
+		httpRoute := &httpRouteList.Items[i]
+		if matches(httpRoute.Annotations, httpRoute.Namespace) {
+			consumers = append(consumers, listConsumer{
+				gatewayApiResource: gatewayApiResource{Name: httpRoute.Name, Namespace: httpRoute.Namespace, Kind: "HTTPRoute"},
+				Object:             httpRoute,
+			})
+		}
+	}
+
+	// Fetch all GRPCRoutes in the cluster
+	var grpcRouteList gatewayv1.GRPCRouteList
+	if err := r.List(ctx, &grpcRouteList); err != nil {
+		return nil, err
+	}
+	for i := range grpcRouteList.Items {
+		grpcRoute := &grpcRouteList.Items[i]
+		if matches(grpcRoute.Annotations, grpcRoute.Namespace) {
+			consumers = append(consumers, listConsumer{
+				gatewayApiResource: gatewayApiResource{Name: grpcRoute.Name, Namespace: grpcRoute.Namespace, Kind: "GRPCRoute"},
+				Object:             grpcRoute,
+			})
+		}
+	}
+
+	// Fetch all Gateways in the cluster
+	var gatewayList gatewayv1.GatewayList
+	if err := r.List(ctx, &gatewayList); err != nil {
+		return nil, err
+	}
+	for i := range gatewayList.Items {
+		gateway := &gatewayList.Items[i]
+		if matches(gateway.Annotations, gateway.Namespace) {
+			consumers = append(consumers, listConsumer{
+				gatewayApiResource: gatewayApiResource{Name: gateway.Name, Namespace: gateway.Namespace, Kind: "Gateway"},
+				Object:             gateway,
+			})
+		}
+	}
+
+	return consumers, nil
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// listReference identifies a list referenced in the lists annotation.
+// Namespace is empty for cluster-scoped lists.
+type listReference struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// parseListReference parses an entry of the lists annotation. Entries are either
+// "name", which resolves in NetworkPoliciesNamespace, "namespace/name", or
+// "local:name", which resolves in localNamespace (the namespace of the route).
+// Entries prefixed with "configmap:" reference a ConfigMap using the same forms,
+// entries prefixed with "addresslist:" reference a cluster-scoped AddressList.
+func parseListReference(entry string, localNamespace string) (listReference, error) {
+	entry = strings.TrimSpace(entry)
+
+	if addressListName, found := strings.CutPrefix(entry, ListReferenceAddressListPrefix); found {
+		if errs := validation.IsDNS1123Subdomain(addressListName); len(errs) > 0 {
+			return listReference{}, fmt.Errorf("invalid name in list reference %q: %s", entry, strings.Join(errs, ", "))
+		}
+		return listReference{Kind: AddressListKind, Name: addressListName}, nil
+	}
+
+	kind := NetworkPolicyKind
+	reference := entry
+	if configMapReference, found := strings.CutPrefix(reference, ListReferenceConfigMapPrefix); found {
+		kind, reference = ConfigMapKind, configMapReference
+	}
+
+	var namespace, name string
+	if localName, found := strings.CutPrefix(reference, ListReferenceLocalPrefix); found {
+		namespace, name = localNamespace, localName
+	} else if ns, n, found := strings.Cut(reference, "/"); found {
+		namespace, name = ns, n
+	} else {
+		namespace, name = NetworkPoliciesNamespace, reference
+	}
+
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return listReference{}, fmt.Errorf("invalid namespace in list reference %q: %s", entry, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return listReference{}, fmt.Errorf("invalid name in list reference %q: %s", entry, strings.Join(errs, ", "))
+	}
+
+	return listReference{Kind: kind, Namespace: namespace, Name: name}, nil
+}
+
+// String returns the reference in "namespace/name" form, prefixed with the kind
+// for references that are not NetworkPolicies.
+func (l listReference) String() string {
+	switch l.Kind {
+	case AddressListKind:
+		return ListReferenceAddressListPrefix + l.Name
+	case ConfigMapKind:
+		return ListReferenceConfigMapPrefix + l.Namespace + "/" + l.Name
+	case ServiceKind:
+		return AddressTokenServicePrefix + l.Namespace + "/" + l.Name
+	default:
+		return l.Namespace + "/" + l.Name
+	}
+}
+
+// group returns the API group of the referenced object.
+func (l listReference) group() string {
+	switch l.Kind {
+	case AddressListKind:
+		return AddressListGroup
+	case ConfigMapKind, ServiceKind:
+		return ""
+	default:
+		return NetworkPolicyGroup
+	}
+}
+
+// requiresReferenceGrant reports whether resolving the list from the given resource
+// crosses a namespace boundary. Lists in NetworkPoliciesNamespace are shared by the
+// platform and never require a grant, neither do cluster-scoped AddressLists.
+func (l listReference) requiresReferenceGrant(gatewayApiResource gatewayApiResource) bool {
+	return l.Namespace != "" &&
+		l.Namespace != gatewayApiResource.Namespace &&
+		l.Namespace != NetworkPoliciesNamespace
+}
+
+// referencesList reports whether the list entries of a resource in
+// localNamespace contain a reference to the target list.
+func referencesList(entries []string, localNamespace string, target listReference) bool {
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		reference, err := parseListReference(entry, localNamespace)
+		if err != nil {
+			continue
+		}
+		if reference == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package controller
+
+import "testing"
+
+func TestParseListReference(t *testing.T) {
+	tests := []struct {
+		entry string
+		want  listReference
+	}{
+		{"office", listReference{Kind: NetworkPolicyKind, Namespace: NetworkPoliciesNamespace, Name: "office"}},
+		{" shared/office ", listReference{Kind: NetworkPolicyKind, Namespace: "shared", Name: "office"}},
+		{"local:office", listReference{Kind: NetworkPolicyKind, Namespace: "default", Name: "office"}},
+		{"configmap:partners", listReference{Kind: ConfigMapKind, Namespace: NetworkPoliciesNamespace, Name: "partners"}},
+		{"configmap:shared/partners", listReference{Kind: ConfigMapKind, Namespace: "shared", Name: "partners"}},
+		{"configmap:local:partners", listReference{Kind: ConfigMapKind, Namespace: "default", Name: "partners"}},
+		{"addresslist:cdn.edges", listReference{Kind: AddressListKind, Name: "cdn.edges"}},
+	}
+	for _, test := range tests {
+		got, err := parseListReference(test.entry, "default")
+		if err != nil {
+			t.Errorf("parseListReference(%q) returned error: %v", test.entry, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseListReference(%q) = %+v, want %+v", test.entry, got, test.want)
+		}
+	}
+
+	for _, entry := range []string{"", "Office", "shared/", "Shared/office", "local:", "configmap:a/b/c", "addresslist:", "addresslist:Edges"} {
+		if _, err := parseListReference(entry, "default"); err == nil {
+			t.Errorf("expected error for %q", entry)
+		}
+	}
+}
+
+func TestRequiresReferenceGrant(t *testing.T) {
+	route := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "api"}
+	for _, test := range []struct {
+		reference listReference
+		want      bool
+	}{
+		{listReference{Kind: NetworkPolicyKind, Namespace: "default", Name: "office"}, false},
+		{listReference{Kind: NetworkPolicyKind, Namespace: NetworkPoliciesNamespace, Name: "office"}, false},
+		{listReference{Kind: AddressListKind, Name: "partners"}, false},
+		{listReference{Kind: ConfigMapKind, Namespace: "shared", Name: "partners"}, true},
+	} {
+		if got := test.reference.requiresReferenceGrant(route); got != test.want {
+			t.Errorf("requiresReferenceGrant(%v) = %v, want %v", test.reference, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// parseListenersAnnotation parses the listeners annotation of a gateway, a JSON or
+// YAML map from listener name to the SecurityPolicy annotations of the listener,
+// without the securitypolicies.vitistack.io/ prefix. The returned annotations have the prefix.
+func parseListenersAnnotation(annotation string) (map[string]map[string]string, error) {
+	var document map[string]map[string]string
+	if err := yaml.UnmarshalStrict([]byte(annotation), &document); err != nil {
+		return nil, fmt.Errorf("unable to parse listeners: %w", err)
+	}
+
+	listeners := make(map[string]map[string]string, len(document))
+	for listener, values := range document {
+		if errs := validation.IsDNS1123Subdomain(listener); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid listener name %q: %s", listener, errs[0])
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("listener %q has no annotations", listener)
+		}
+		annotations := make(map[string]string, len(values))
+		for key, value := range values {
+			annotation := AnnotationSecurityPolicyPrefix + key
+			if !slices.Contains(securityPolicyAnnotations, annotation) {
+				return nil, fmt.Errorf("listener %q: %s is not supported for listeners", listener, annotation)
+			}
+			annotations[annotation] = value
+		}
+		listeners[listener] = annotations
+	}
+
+	return listeners, nil
+}
+
+// annotationSets returns the annotations of a route or gateway followed by the
+// annotations of each of its listeners. An invalid listeners annotation is skipped.
+func annotationSets(annotations map[string]string) []map[string]string {
+	sets := []map[string]string{annotations}
+	if annotation, ok := annotations[AnnotationSecurityPolicyListeners]; ok {
+		if listeners, err := parseListenersAnnotation(annotation); err == nil {
+			for _, listener := range slices.Sorted(maps.Keys(listeners)) {
+				sets = append(sets, listeners[listener])
+			}
+		}
+	}
+	return sets
+}
+
+// reconcileListenerSecurityPolicies creates and updates a SecurityPolicy per listener
+// of the listeners annotation, targeting the listener with sectionName, and deletes the
+// SecurityPolicies of listeners that were removed. Listeners are reconciled independently,
+// an invalid listener does not affect the others.
+func reconcileListenerSecurityPolicies(ctx context.Context, r client.Client, dnsResolver *DNSResolver, gatewayApiResource gatewayApiResource, listeners []gatewayv1.Listener, annotations map[string]string) error {
+	var listenerAnnotations map[string]map[string]string
+	if annotation, ok := annotations[AnnotationSecurityPolicyListeners]; ok {
+		parsed, err := parseListenersAnnotation(annotation)
+		if err != nil {
+			return fmt.Errorf("invalid %s annotation: %w", AnnotationSecurityPolicyListeners, err)
+		}
+		listenerAnnotations = parsed
+	}
+
+	var errs []error
+	names := slices.Sorted(maps.Keys(listenerAnnotations))
+	for _, name := range names {
+		if !slices.ContainsFunc(listeners, func(listener gatewayv1.Listener) bool { return string(listener.Name) == name }) {
+			errs = append(errs, fmt.Errorf("listener %q not found in %s %s/%s", name, gatewayApiResource.Kind, gatewayApiResource.Namespace, gatewayApiResource.Name))
+			continue
+		}
+
+		listenerResource := gatewayApiResource
+		listenerResource.SectionName = name
+
+		securityPolicy, err := getSecurityPolicy(ctx, r, listenerResource)
+		if err != nil {
+			securityPolicy, err = createSecurityPolicy(ctx, r, listenerResource)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("listener %q: %w", name, err))
+				continue
+			}
+		}
+
+		if err := updateSecurityPolicy(ctx, r, dnsResolver, listenerResource, securityPolicy, listenerAnnotations[name]); err != nil {
+			errs = append(errs, fmt.Errorf("listener %q: %w", name, err))
+		}
+	}
+
+	if err := deleteListenerSecurityPolicies(ctx, r, dnsResolver, gatewayApiResource, names...); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteListenerSecurityPolicies deletes the SecurityPolicies created for the listeners
+// of a gateway, except for the listeners in keep, and stops resolving their dns: entries.
+func deleteListenerSecurityPolicies(ctx context.Context, r client.Client, dnsResolver *DNSResolver, gatewayApiResource gatewayApiResource, keep ...string) error {
+	securityPolicyList := &envoyv1.SecurityPolicyList{}
+	if err := r.List(ctx, securityPolicyList, client.InNamespace(gatewayApiResource.Namespace)); err != nil {
+		return err
+	}
+
+	var sectionNames []string
+	for _, securityPolicy := range securityPolicyList.Items {
+		for _, targetRef := range securityPolicy.Spec.TargetRefs {
+			if targetRef.SectionName == nil ||
+				string(targetRef.Name) != gatewayApiResource.Name ||
+				string(targetRef.Kind) != gatewayApiResource.Kind {
+				continue
+			}
+			sectionName := string(*targetRef.SectionName)
+			listenerResource := gatewayApiResource
+			listenerResource.SectionName = sectionName
+			if securityPolicy.Name != listenerResource.securityPolicyName() {
+				continue
+			}
+			if !slices.Contains(keep, sectionName) && !slices.Contains(sectionNames, sectionName) {
+				sectionNames = append(sectionNames, sectionName)
+			}
+		}
+	}
+
+	for _, sectionName := range sectionNames {
+		listenerResource := gatewayApiResource
+		listenerResource.SectionName = sectionName
+		if err := deleteSecurityPolicy(ctx, r, listenerResource); err != nil {
+			return err
+		}
+		forgetDNSHosts(ctx, dnsResolver, listenerResource)
+	}
+
+	return nil
+}
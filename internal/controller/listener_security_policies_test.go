@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestParseListenersAnnotation(t *testing.T) {
+	listeners, err := parseListenersAnnotation("admin:\n  lists: local:admins\n  default-action: deny\nhttps:\n  addresses: 10.0.0.0/8\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listeners) != 2 || listeners["admin"][AnnotationSecurityPolicyLists] != "local:admins" || listeners["https"][AnnotationSecurityPolicyAddresses] != "10.0.0.0/8" {
+		t.Errorf("got %v", listeners)
+	}
+
+	for _, annotation := range []string{
+		"admin: local:admins",
+		"admin: {}",
+		"Admin_Listener:\n  lists: local:admins",
+		"admin:\n  listeners: x",
+		"admin:\n  rate-limit: 100/minute",
+	} {
+		if _, err := parseListenersAnnotation(annotation); err == nil {
+			t.Errorf("expected error for %q", annotation)
+		}
+	}
+}
+
+func TestReconcileListenerSecurityPolicies(t *testing.T) {
+	ctx := context.Background()
+	gateway := gatewayApiResource{Kind: "Gateway", Namespace: "default", Name: "public"}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := envoyv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	securityPolicy := func(name string, sectionName string) *envoyv1.SecurityPolicy {
+		targetRef := gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+			LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{Group: GatewayAPIGroup, Kind: "Gateway", Name: "public"},
+		}
+		if sectionName != "" {
+			section := gatewayv1.SectionName(sectionName)
+			targetRef.SectionName = &section
+		}
+		return &envoyv1.SecurityPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: envoyv1.SecurityPolicySpec{
+				PolicyTargetReferences: envoyv1.PolicyTargetReferences{
+					TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{targetRef},
+				},
+			},
+		}
+	}
+	adminResource, oldResource := gateway, gateway
+	adminResource.SectionName, oldResource.SectionName = "admin", "old"
+	r := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		securityPolicy("gateway-public", ""),
+		securityPolicy(adminResource.securityPolicyName(), "admin"),
+		securityPolicy(oldResource.securityPolicyName(), "old"),
+		securityPolicy("custom", "https"),
+	).Build()
+
+	listeners := []gatewayv1.Listener{{Name: "https"}, {Name: "admin"}}
+	if err := reconcileListenerSecurityPolicies(ctx, r, nil, gateway, listeners, map[string]string{
+		AnnotationSecurityPolicyListeners: "admin:\n  addresses: 10.0.0.0/8\n",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var admin envoyv1.SecurityPolicy
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: adminResource.securityPolicyName()}, &admin); err != nil {
+		t.Fatal(err)
+	}
+	if admin.Spec.Authorization == nil || admin.Spec.Authorization.Rules[0].Principal.ClientCIDRs[0] != "10.0.0.0/8" {
+		t.Errorf("got %+v", admin.Spec.Authorization)
+	}
+
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: oldResource.securityPolicyName()}, &envoyv1.SecurityPolicy{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected SecurityPolicy of removed listener to be deleted, got %v", err)
+	}
+	for _, name := range []string{"gateway-public", "custom"} {
+		if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, &envoyv1.SecurityPolicy{}); err != nil {
+			t.Errorf("expected SecurityPolicy %s to be kept, got %v", name, err)
+		}
+	}
+
+	if err := reconcileListenerSecurityPolicies(ctx, r, nil, gateway, listeners, map[string]string{
+		AnnotationSecurityPolicyListeners: "internal:\n  addresses: 10.0.0.0/8\n",
+	}); err == nil {
+		t.Error("expected error for unknown listener")
+	}
+}
+
+func TestListenerSecurityPolicyName(t *testing.T) {
+	listener := gatewayApiResource{Kind: "Gateway", Namespace: "default", Name: "public", SectionName: "admin"}
+	other := gatewayApiResource{Kind: "Gateway", Namespace: "default", Name: "public-admin"}
+	dashed := gatewayApiResource{Kind: "Gateway", Namespace: "default", Name: "public-admin", SectionName: "x"}
+	shifted := gatewayApiResource{Kind: "Gateway", Namespace: "default", Name: "public", SectionName: "admin-x"}
+
+	if listener.securityPolicyName() == other.securityPolicyName() {
+		t.Errorf("listener and gateway SecurityPolicy names collide: %s", listener.securityPolicyName())
+	}
+	if dashed.securityPolicyName() == shifted.securityPolicyName() {
+		t.Errorf("listener SecurityPolicy names collide: %s", dashed.securityPolicyName())
+	}
+}
+
+func TestCreateSecurityPolicyRefusesOtherTarget(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := envoyv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	other := gatewayApiResource{Kind: "Gateway", Namespace: "default", Name: "other"}
+	r := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&envoyv1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gateway-public"},
+		Spec: envoyv1.SecurityPolicySpec{
+			PolicyTargetReferences: envoyv1.PolicyTargetReferences{
+				TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{{
+					LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{Group: GatewayAPIGroup, Kind: "Gateway", Name: gatewayv1.ObjectName(other.Name)},
+				}},
+			},
+		},
+	}).Build()
+
+	if _, err := createSecurityPolicy(ctx, r, gatewayApiResource{Kind: "Gateway", Namespace: "default", Name: "public"}); err == nil {
+		t.Error("expected error for SecurityPolicy targeting another Gateway")
+	}
+}
@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// errNoSuchHost is returned when the nameserver reports that the host does not exist.
+var errNoSuchHost = errors.New("no such host")
+
+// lookupHost resolves the A and AAAA records of host using the nameservers in
+// /etc/resolv.conf. Unlike net.Resolver it also returns the lowest TTL of the
+// answers, so the result can be cached for as long as the records are valid.
+// The host is always treated as fully qualified: the search domains and ndots
+// option of resolv.conf are not applied, so a Service must be given by its full
+// name, e.g. svc.ns.svc.cluster.local.
+func lookupHost(ctx context.Context, host string) ([]netip.Addr, time.Duration, error) {
+	return lookupHostWith(ctx, nameservers(ResolvConfPath), host)
+}
+
+// lookupHostWith resolves host like lookupHost, using the given nameservers. A host
+// with records of only one type resolves even if the query for the other type fails.
+func lookupHostWith(ctx context.Context, servers []string, host string) ([]netip.Addr, time.Duration, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, DNSLookupTimeout)
+	defer cancel()
+
+	name, err := dnsmessage.NewName(strings.TrimSuffix(host, ".") + ".")
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid host %q: %w", host, err)
+	}
+
+	var addrs []netip.Addr
+	var ttl time.Duration
+	var seen bool
+	var errs []error
+	for _, queryType := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		response, err := exchange(ctx, servers, name, queryType)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, answer := range response.Answers {
+			// The lowest TTL of the answer chain, including CNAMEs, bounds the cache lifetime
+			answerTTL := time.Duration(answer.Header.TTL) * time.Second
+			if !seen || answerTTL < ttl {
+				ttl = answerTTL
+				seen = true
+			}
+
+			switch body := answer.Body.(type) {
+			case *dnsmessage.AResource:
+				addrs = append(addrs, netip.AddrFrom4(body.A))
+			case *dnsmessage.AAAAResource:
+				addrs = append(addrs, netip.AddrFrom16(body.AAAA))
+			}
+		}
+	}
+
+	if len(addrs) == 0 {
+		if len(errs) > 0 {
+			return nil, 0, fmt.Errorf("unable to resolve %q: %w", host, errs[0])
+		}
+		return nil, 0, fmt.Errorf("unable to resolve %q: no A or AAAA records", host)
+	}
+
+	return addrs, ttl, nil
+}
+
+// nameservers returns the nameservers configured in the resolv.conf at path.
+// It falls back to the local resolver if none are configured.
+func nameservers(path string) []string {
+	var servers []string
+
+	file, err := os.Open(path)
+	if err == nil {
+		defer func() { _ = file.Close() }()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "nameserver" {
+				servers = append(servers, net.JoinHostPort(fields[1], "53"))
+			}
+		}
+	}
+
+	if len(servers) == 0 {
+		servers = []string{"127.0.0.1:53"}
+	}
+
+	return servers
+}
+
+// exchange sends the query to each server in turn until one answers.
+// Truncated UDP responses are retried over TCP.
+func exchange(ctx context.Context, servers []string, name dnsmessage.Name, queryType dnsmessage.Type) (*dnsmessage.Message, error) {
+
+	id := uint16(rand.N(1 << 16))
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: queryType, Class: dnsmessage.ClassINET}},
+	}
+
+	// Advertise a larger UDP payload size to avoid falling back to TCP for larger answers
+	var optHeader dnsmessage.ResourceHeader
+	if err := optHeader.SetEDNS0(1232, dnsmessage.RCodeSuccess, false); err != nil {
+		return nil, err
+	}
+	query.Additionals = []dnsmessage.Resource{{Header: optHeader, Body: &dnsmessage.OPTResource{}}}
+
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	lastErr := errors.New("no nameservers")
+	for _, server := range servers {
+		response, err := exchangeWith(ctx, "udp", server, packed)
+		if err == nil && response.Truncated {
+			response, err = exchangeWith(ctx, "tcp", server, packed)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if response.ID != id || !response.Response || !answersQuestion(response, query.Questions[0]) {
+			lastErr = fmt.Errorf("invalid response from %s", server)
+			continue
+		}
+
+		switch response.RCode {
+		case dnsmessage.RCodeSuccess:
+			return response, nil
+		case dnsmessage.RCodeNameError:
+			return nil, errNoSuchHost
+		default:
+			lastErr = fmt.Errorf("nameserver %s returned %s", server, response.RCode)
+		}
+	}
+
+	return nil, lastErr
+}
+
+// answersQuestion reports whether response is the answer to question, so a response
+// to another query with the same ID is not taken for it.
+func answersQuestion(response *dnsmessage.Message, question dnsmessage.Question) bool {
+	return len(response.Questions) == 1 &&
+		strings.EqualFold(response.Questions[0].Name.String(), question.Name.String()) &&
+		response.Questions[0].Type == question.Type &&
+		response.Questions[0].Class == question.Class
+}
+
+// exchangeWith sends a packed query to server over the given network and parses the response.
+func exchangeWith(ctx context.Context, network string, server string, packed []byte) (*dnsmessage.Message, error) {
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	var buffer []byte
+	if network == "tcp" {
+		// Messages over TCP are prefixed with their length
+		if _, err := conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(packed))), packed...)); err != nil {
+			return nil, err
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		buffer = make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, buffer); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := conn.Write(packed); err != nil {
+			return nil, err
+		}
+		buffer = make([]byte, 1232)
+		n, err := conn.Read(buffer)
+		if err != nil {
+			return nil, err
+		}
+		buffer = buffer[:n]
+	}
+
+	var response dnsmessage.Message
+	if err := response.Unpack(buffer); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// serveDNS answers the queries sent to the returned UDP address with respond,
+// which may modify the response to the query.
+func serveDNS(t *testing.T, respond func(query dnsmessage.Message, response *dnsmessage.Message)) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buffer := make([]byte, 1232)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buffer[:n]); err != nil {
+				continue
+			}
+			response := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true},
+				Questions: query.Questions,
+			}
+			respond(query, &response)
+			packed, err := response.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestLookupHost(t *testing.T) {
+	ctx := context.Background()
+	a := func(name dnsmessage.Name, ttl uint32, addr [4]byte) dnsmessage.Resource {
+		return dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+			Body:   &dnsmessage.AResource{A: addr},
+		}
+	}
+	aaaa := func(name dnsmessage.Name, ttl uint32, addr [16]byte) dnsmessage.Resource {
+		return dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeAAAA, Class: dnsmessage.ClassINET, TTL: ttl},
+			Body:   &dnsmessage.AAAAResource{AAAA: addr},
+		}
+	}
+	v6 := netip.MustParseAddr("2001:db8::1").As16()
+
+	t.Run("zero ttl", func(t *testing.T) {
+		server := serveDNS(t, func(query dnsmessage.Message, response *dnsmessage.Message) {
+			question := query.Questions[0]
+			if question.Type == dnsmessage.TypeA {
+				response.Answers = []dnsmessage.Resource{a(question.Name, 0, [4]byte{192, 0, 2, 1})}
+			} else {
+				response.Answers = []dnsmessage.Resource{aaaa(question.Name, 600, v6)}
+			}
+		})
+		addrs, ttl, err := lookupHostWith(ctx, []string{server}, "partner.example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ttl != 0 {
+			t.Errorf("expected the TTL 0 answer to bound the TTL, got %s", ttl)
+		}
+		want := []netip.Addr{netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("2001:db8::1")}
+		if !slices.Equal(addrs, want) {
+			t.Errorf("got %v, want %v", addrs, want)
+		}
+	})
+
+	t.Run("aaaa failure", func(t *testing.T) {
+		server := serveDNS(t, func(query dnsmessage.Message, response *dnsmessage.Message) {
+			question := query.Questions[0]
+			if question.Type == dnsmessage.TypeA {
+				response.Answers = []dnsmessage.Resource{a(question.Name, 300, [4]byte{192, 0, 2, 1})}
+			} else {
+				response.RCode = dnsmessage.RCodeServerFailure
+			}
+		})
+		addrs, ttl, err := lookupHostWith(ctx, []string{server}, "partner.example.com")
+		if err != nil {
+			t.Fatalf("expected the A records despite the failed AAAA query, got %v", err)
+		}
+		if ttl != 300*time.Second || !slices.Equal(addrs, []netip.Addr{netip.MustParseAddr("192.0.2.1")}) {
+			t.Errorf("got %v, %s", addrs, ttl)
+		}
+	})
+
+	t.Run("both fail", func(t *testing.T) {
+		server := serveDNS(t, func(query dnsmessage.Message, response *dnsmessage.Message) {
+			response.RCode = dnsmessage.RCodeServerFailure
+		})
+		if _, _, err := lookupHostWith(ctx, []string{server}, "partner.example.com"); err == nil {
+			t.Error("expected error when both queries fail")
+		}
+	})
+
+	t.Run("other question", func(t *testing.T) {
+		other := dnsmessage.MustNewName("other.example.com.")
+		server := serveDNS(t, func(query dnsmessage.Message, response *dnsmessage.Message) {
+			question := query.Questions[0]
+			question.Name = other
+			response.Questions = []dnsmessage.Question{question}
+			if question.Type == dnsmessage.TypeA {
+				response.Answers = []dnsmessage.Resource{a(other, 300, [4]byte{198, 51, 100, 1})}
+			}
+		})
+		if addrs, _, err := lookupHostWith(ctx, []string{server}, "partner.example.com"); err == nil {
+			t.Errorf("expected the answer to another question to be rejected, got %v", addrs)
+		}
+	})
+
+	t.Run("fully qualified", func(t *testing.T) {
+		var mutex sync.Mutex
+		var names []string
+		server := serveDNS(t, func(query dnsmessage.Message, response *dnsmessage.Message) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			names = append(names, query.Questions[0].Name.String())
+			response.RCode = dnsmessage.RCodeNameError
+		})
+		if _, _, err := lookupHostWith(ctx, []string{server}, "svc.ns"); err == nil {
+			t.Error("expected error for a name that only resolves with search domains")
+		}
+		// Search domains of resolv.conf are not applied
+		mutex.Lock()
+		defer mutex.Unlock()
+		if len(names) == 0 || slices.ContainsFunc(names, func(name string) bool { return name != "svc.ns." }) {
+			t.Errorf("expected only queries for svc.ns., got %v", names)
+		}
+	})
+}
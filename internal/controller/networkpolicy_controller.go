@@ -18,8 +18,6 @@ package controller
 
 import (
 	"context"
-	"slices"
-	"strings"
 
 	v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -28,7 +26,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
-	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // NetworkPolicyReconciler reconciles a NetworkPolicy object
@@ -60,76 +57,24 @@ func (r *NetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// Fetch all HttpRoutes in the cluster
-	var httpRouteList gatewayv1.HTTPRouteList
-	if err := r.List(ctx, &httpRouteList); err != nil {
-		log.Error(err, "Failed to list HTTPRoutes")
+	// Find all HttpRoutes, GRPCRoutes and Gateways that reference the NetworkPolicy
+	consumers, err := listConsumers(ctx, r.Client, listReference{
+		Kind:      NetworkPolicyKind,
+		Namespace: networkPolicy.Namespace,
+		Name:      networkPolicy.Name,
+	})
+	if err != nil {
+		log.Error(err, "Failed to list consumers of NetworkPolicy")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// Fetch all GRPCRoutes in the cluster
-	var grpcRouteList gatewayv1.GRPCRouteList
-	if err := r.List(ctx, &grpcRouteList); err != nil {
-		log.Error(err, "Failed to list GRPCRoutes")
-		return ctrl.Result{}, client.IgnoreNotFound(err)
-	}
-
-	// Fetch all Gateways in the cluster
-	var gatewayList gatewayv1.GatewayList
-	if err := r.List(ctx, &gatewayList); err != nil {
-		log.Error(err, "Failed to list Gateways")
-		return ctrl.Result{}, client.IgnoreNotFound(err)
-	}
-
-	// Loop through all HttpRoutes to find those that reference NetworkPolicy Name
-	for _, httpRoute := range httpRouteList.Items {
-		if _, ok := httpRoute.Annotations[AnnotationSecurityPolicyLists]; ok {
-			// Create slice of AnnotationSecurityPolicyLists entries
-			annotationLists := httpRoute.Annotations[AnnotationSecurityPolicyLists]
-			listEntries := strings.Split(annotationLists, ",")
-			if slices.Contains(listEntries, networkPolicy.Name) {
-				// Update the HttpRoute to trigger reconciliation
-				if err := notifyController(ctx, r.Client, &httpRoute); err != nil {
-					log.Error(err, "Failed to notify HttpRoute", "HttpRoute.Namespace", httpRoute.Namespace, "HttpRoute.Name", httpRoute.Name)
-					return ctrl.Result{}, err
-				}
-				log.Info("Patched HttpRoute due to NetworkPolicy change", "HttpRoute.Namespace", httpRoute.Namespace, "HttpRoute.Name", httpRoute.Name)
-			}
-		}
-	}
-
-	// Loop through all GRPCRoutes to find those that reference NetworkPolicy Name
-	for _, grpcRoute := range grpcRouteList.Items {
-		if _, ok := grpcRoute.Annotations[AnnotationSecurityPolicyLists]; ok {
-			// Create slice of AnnotationSecurityPolicyLists entries
-			annotationLists := grpcRoute.Annotations[AnnotationSecurityPolicyLists]
-			listEntries := strings.Split(annotationLists, ",")
-			if slices.Contains(listEntries, networkPolicy.Name) {
-				// Update the grpcRoute to trigger reconciliation
-				if err := notifyController(ctx, r.Client, &grpcRoute); err != nil {
-					log.Error(err, "Failed to notify grpcRoute", "grpcRoute.Namespace", grpcRoute.Namespace, "grpcRoute.Name", grpcRoute.Name)
-					return ctrl.Result{}, err
-				}
-				log.Info("Patched GRPCRoute due to NetworkPolicy change", "GRPCRoute.Namespace", grpcRoute.Namespace, "GRPCRoute.Name", grpcRoute.Name)
-			}
-		}
-	}
-
-	// Loop through all Gateways to find those that reference NetworkPolicy Name
-	for _, gateway := range gatewayList.Items {
-		if _, ok := gateway.Annotations[AnnotationSecurityPolicyLists]; ok {
-			// Create slice of AnnotationSecurityPolicyLists entries
-			annotationLists := gateway.Annotations[AnnotationSecurityPolicyLists]
-			listEntries := strings.Split(annotationLists, ",")
-			if slices.Contains(listEntries, networkPolicy.Name) {
-				// Update the gateway to trigger reconciliation
-				if err := notifyController(ctx, r.Client, &gateway); err != nil {
-					log.Error(err, "Failed to notify Gateway", "Gateway.Namespace", gateway.Namespace, "Gateway.Name", gateway.Name)
-					return ctrl.Result{}, err
-				}
-				log.Info("Patched Gateway due to NetworkPolicy change", "Gateway.Namespace", gateway.Namespace, "Gateway.Name", gateway.Name)
-			}
+	// Update each consumer to trigger reconciliation
+	for _, consumer := range consumers {
+		if err := notifyController(ctx, r.Client, consumer.Object); err != nil {
+			log.Error(err, "Failed to notify "+consumer.Kind, consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+			return ctrl.Result{}, err
 		}
+		log.Info("Patched "+consumer.Kind+" due to NetworkPolicy change", consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
 	}
 
 	return ctrl.Result{}, nil
@@ -138,13 +83,14 @@ func (r *NetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 // SetupWithManager sets up the controller with the Manager.
 func (r *NetworkPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
-	// Predicate that filters updates where only annotations changed
+	// Predicate that filters out deletes. Lists may live in any namespace when
+	// referenced as namespace/name, so NetworkPolicies are watched cluster-wide.
 	annotationChangedPredicate := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			return e.ObjectNew.GetNamespace() == NetworkPoliciesNamespace
+			return true
 		},
 		CreateFunc: func(e event.CreateEvent) bool {
-			return e.Object.GetNamespace() == NetworkPoliciesNamespace
+			return true
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			return false
@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+)
+
+// NodeReconciler reconciles a Node object
+type NodeReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// The addresses and pod CIDRs of all Nodes when consumers were last notified.
+	// Reconciles of a controller do not run concurrently.
+	resolved         bool
+	resolvedNodes    []string
+	resolvedPodCIDRs []string
+}
+
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+
+// Reconcile triggers reconciliation of all routes and gateways that use the
+// @nodes or @pod-cidrs tokens in their addresses annotation. Deleted Nodes are
+// reconciled as well, since their addresses must be removed. Consumers are only
+// notified when the tokens resolve to other CIDRs, so a Node event does not cause
+// an update per route when nothing changed. The first reconcile only records the
+// CIDRs, since routes and gateways are reconciled on startup anyway.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
+func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	log.Info("Reconciling Node", "Node.Name", req.Name)
+
+	// Skip notifying consumers if the tokens resolve to the same CIDRs
+	nodes, err := resolveAddressToken(ctx, r.Client, gatewayApiResource{}, addressToken{Name: AddressTokenNodes})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	podCIDRs, err := resolveAddressToken(ctx, r.Client, gatewayApiResource{}, addressToken{Name: AddressTokenPodCIDRs})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	nodes, podCIDRs = utils.SortSlice(nodes), utils.SortSlice(podCIDRs)
+	if !r.resolved || (slices.Equal(nodes, r.resolvedNodes) && slices.Equal(podCIDRs, r.resolvedPodCIDRs)) {
+		r.resolved, r.resolvedNodes, r.resolvedPodCIDRs = true, nodes, podCIDRs
+		return ctrl.Result{}, nil
+	}
+
+	// Find all HttpRoutes, GRPCRoutes and Gateways that use the Node tokens
+	consumers, err := addressTokenConsumers(ctx, r.Client,
+		addressToken{Name: AddressTokenNodes},
+		addressToken{Name: AddressTokenPodCIDRs},
+	)
+	if err != nil {
+		log.Error(err, "Failed to list consumers of Nodes")
+		return ctrl.Result{}, err
+	}
+
+	// Update each consumer to trigger reconciliation
+	for _, consumer := range consumers {
+		if err := notifyController(ctx, r.Client, consumer.Object); err != nil {
+			log.Error(err, "Failed to notify "+consumer.Kind, consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+			return ctrl.Result{}, err
+		}
+		log.Info("Patched "+consumer.Kind+" due to Node change", consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+	}
+	r.resolvedNodes, r.resolvedPodCIDRs = nodes, podCIDRs
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	// Predicate that filters updates where neither the addresses nor the pod CIDRs
+	// changed, Node status is updated frequently by the kubelet.
+	addressesChangedPredicate := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, okOld := e.ObjectOld.(*corev1.Node)
+			newNode, okNew := e.ObjectNew.(*corev1.Node)
+			if !okOld || !okNew {
+				return false
+			}
+			return !reflect.DeepEqual(oldNode.Status.Addresses, newNode.Status.Addresses) ||
+				!reflect.DeepEqual(oldNode.Spec.PodCIDRs, newNode.Spec.PodCIDRs) ||
+				oldNode.Spec.PodCIDR != newNode.Spec.PodCIDR
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Node{}).
+		Named("node").
+		WithEventFilter(addressesChangedPredicate).
+		Complete(r)
+}
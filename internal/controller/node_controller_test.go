@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// newConsumerTestClient returns a fake client holding route and objects.
+func newConsumerTestClient(t *testing.T, route *gatewayv1.HTTPRoute, objects ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayv1.Install(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, route)...).Build()
+}
+
+// consumerNotified reports whether the route was notified since markNotified.
+func consumerNotified(t *testing.T, r client.Client, route *gatewayv1.HTTPRoute) bool {
+	t.Helper()
+	var latest gatewayv1.HTTPRoute
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(route), &latest); err != nil {
+		t.Fatal(err)
+	}
+	return latest.Annotations[AnnotationSecurityPolicyLastUpdated] == ""
+}
+
+// markNotified resets the notification of the route.
+func markNotified(t *testing.T, r client.Client, route *gatewayv1.HTTPRoute) {
+	t.Helper()
+	var latest gatewayv1.HTTPRoute
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(route), &latest); err != nil {
+		t.Fatal(err)
+	}
+	latest.Annotations[AnnotationSecurityPolicyLastUpdated] = "reconciled"
+	if err := r.Update(context.Background(), &latest); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNodeReconcileNotifiesOnChange(t *testing.T) {
+	ctx := context.Background()
+	route := &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "api",
+		Annotations: map[string]string{
+			AnnotationSecurityPolicyAddresses:   "@nodes",
+			AnnotationSecurityPolicyLastUpdated: "reconciled",
+		},
+	}}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	}
+	r := newConsumerTestClient(t, route, node)
+	reconciler := &NodeReconciler{Client: r}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "node-1"}}
+
+	// The first reconcile only records the addresses
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if consumerNotified(t, r, route) {
+		t.Error("expected the first reconcile not to notify consumers")
+	}
+
+	// Reconciles without changes do not notify
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if consumerNotified(t, r, route) {
+		t.Error("expected unchanged Nodes not to notify consumers")
+	}
+
+	node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "192.0.2.1"})
+	if err := r.Status().Update(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if !consumerNotified(t, r, route) {
+		t.Error("expected changed Node addresses to notify consumers")
+	}
+	markNotified(t, r, route)
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if consumerNotified(t, r, route) {
+		t.Error("expected consumers to be notified once per change")
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// oidcAnnotations are the annotations that configure OIDC.
+var oidcAnnotations = []string{
+	AnnotationSecurityPolicyOIDCIssuer,
+	AnnotationSecurityPolicyOIDCClientID,
+	AnnotationSecurityPolicyOIDCClientSecret,
+	AnnotationSecurityPolicyOIDCScopes,
+	AnnotationSecurityPolicyOIDCRedirectURL,
+}
+
+// getOIDC returns the OIDC authentication of the SecurityPolicy described by the oidc-
+// annotations, or nil if OIDC is not configured. The client Secret must exist in the
+// namespace of the route or gateway and hold the client-secret key. Without the
+// oidc-client-id annotation, the client ID is read from the client-id key of the Secret.
+func getOIDC(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, annotations map[string]string) (*envoyv1.OIDC, error) {
+	configured := slices.ContainsFunc(oidcAnnotations, func(annotation string) bool {
+		return annotations[annotation] != ""
+	})
+	if !configured {
+		return nil, nil
+	}
+
+	issuer := strings.TrimSpace(annotations[AnnotationSecurityPolicyOIDCIssuer])
+	if issuer == "" {
+		return nil, fmt.Errorf("%s is required to configure OIDC", AnnotationSecurityPolicyOIDCIssuer)
+	}
+	if parsed, err := url.Parse(issuer); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("%s not valid: %q. Expected an https URL", AnnotationSecurityPolicyOIDCIssuer, issuer)
+	}
+
+	secretName := strings.TrimSpace(annotations[AnnotationSecurityPolicyOIDCClientSecret])
+	if secretName == "" {
+		return nil, fmt.Errorf("%s is required to configure OIDC", AnnotationSecurityPolicyOIDCClientSecret)
+	}
+	var secret corev1.Secret
+	if err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: gatewayApiResource.Namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("unable to get OIDC client Secret %s/%s: %w", gatewayApiResource.Namespace, secretName, err)
+	}
+	if len(secret.Data[envoyv1.OIDCClientSecretKey]) == 0 {
+		return nil, fmt.Errorf("OIDC client Secret %s/%s has no %s key", secret.Namespace, secret.Name, envoyv1.OIDCClientSecretKey)
+	}
+
+	oidc := &envoyv1.OIDC{
+		Provider:     envoyv1.OIDCProvider{Issuer: issuer},
+		ClientSecret: gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(secretName)},
+		Scopes:       annotationEntries(annotations, AnnotationSecurityPolicyOIDCScopes),
+	}
+
+	if clientID := strings.TrimSpace(annotations[AnnotationSecurityPolicyOIDCClientID]); clientID != "" {
+		oidc.ClientID = &clientID
+	} else if len(secret.Data[envoyv1.OIDCClientIDKey]) > 0 {
+		oidc.ClientIDRef = &gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(secretName)}
+	} else {
+		return nil, fmt.Errorf("%s is required, OIDC client Secret %s/%s has no %s key", AnnotationSecurityPolicyOIDCClientID, secret.Namespace, secret.Name, envoyv1.OIDCClientIDKey)
+	}
+
+	if redirectURL := strings.TrimSpace(annotations[AnnotationSecurityPolicyOIDCRedirectURL]); redirectURL != "" {
+		// Redirect URLs may be built from the request, e.g. %REQ(:authority)%
+		if !strings.Contains(redirectURL, "%REQ(") {
+			if parsed, err := url.Parse(redirectURL); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+				return nil, fmt.Errorf("%s not valid: %q. Expected an http or https URL", AnnotationSecurityPolicyOIDCRedirectURL, redirectURL)
+			}
+		}
+		oidc.RedirectURL = &redirectURL
+	}
+
+	return oidc, nil
+}
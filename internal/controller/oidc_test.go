@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetOIDC(t *testing.T) {
+	ctx := context.Background()
+	route := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "dashboard"}
+	r := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dashboard-oidc"},
+			Data:       map[string][]byte{"client-id": []byte("dashboard"), "client-secret": []byte("s3cr3t")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "no-client-secret"},
+			Data:       map[string][]byte{"client-id": []byte("dashboard")},
+		},
+	).Build()
+
+	oidc, err := getOIDC(ctx, r, route, map[string]string{
+		AnnotationSecurityPolicyOIDCIssuer:       "https://idp.example.com/realms/internal",
+		AnnotationSecurityPolicyOIDCClientSecret: "dashboard-oidc",
+		AnnotationSecurityPolicyOIDCScopes:       "email,profile",
+		AnnotationSecurityPolicyOIDCRedirectURL:  "https://dashboard.example.com/oauth2/callback",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oidc.ClientID != nil || oidc.ClientIDRef == nil || oidc.ClientSecret.Name != "dashboard-oidc" || len(oidc.Scopes) != 2 {
+		t.Errorf("got %+v, want the client ID from the Secret", oidc)
+	}
+
+	for _, annotations := range []map[string]string{
+		{AnnotationSecurityPolicyOIDCClientSecret: "dashboard-oidc"},
+		{AnnotationSecurityPolicyOIDCIssuer: "http://idp.example.com", AnnotationSecurityPolicyOIDCClientSecret: "dashboard-oidc"},
+		{AnnotationSecurityPolicyOIDCIssuer: "https://idp.example.com"},
+		{AnnotationSecurityPolicyOIDCIssuer: "https://idp.example.com", AnnotationSecurityPolicyOIDCClientSecret: "missing"},
+		{AnnotationSecurityPolicyOIDCIssuer: "https://idp.example.com", AnnotationSecurityPolicyOIDCClientSecret: "no-client-secret"},
+		{AnnotationSecurityPolicyOIDCIssuer: "https://idp.example.com", AnnotationSecurityPolicyOIDCClientSecret: "dashboard-oidc", AnnotationSecurityPolicyOIDCRedirectURL: "/oauth2/callback"},
+	} {
+		if _, err := getOIDC(ctx, r, route, annotations); err == nil {
+			t.Errorf("expected error for %v", annotations)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+)
+
+// rateLimitAnnotations are the annotations that configure the BackendTrafficPolicy
+// of a route or gateway.
+var rateLimitAnnotations = []string{
+	AnnotationSecurityPolicyRateLimit,
+	AnnotationSecurityPolicyRateLimitTiers,
+	AnnotationSecurityPolicyRateLimitType,
+}
+
+// maxRateLimitRules are the maximum number of rules of a local and a global rate limit.
+var maxRateLimitRules = map[string]int{
+	RateLimitTypeLocal:  16,
+	RateLimitTypeGlobal: 128,
+}
+
+// rateLimitTier is an entry of the rate-limit-tiers annotation, the limit of the
+// clients in a list.
+type rateLimitTier struct {
+	list  string
+	limit envoyv1.RateLimitValue
+}
+
+// hasRateLimitAnnotations reports whether any annotation configuring the
+// BackendTrafficPolicy is set.
+func hasRateLimitAnnotations(annotations map[string]string) bool {
+	return slices.ContainsFunc(rateLimitAnnotations, func(annotation string) bool {
+		return annotations[annotation] != ""
+	})
+}
+
+// rateLimitAnnotationsChanged reports whether any annotation configuring the
+// BackendTrafficPolicy differs between oldAnnotations and newAnnotations.
+func rateLimitAnnotationsChanged(oldAnnotations map[string]string, newAnnotations map[string]string) bool {
+	return slices.ContainsFunc(rateLimitAnnotations, func(annotation string) bool {
+		return oldAnnotations[annotation] != newAnnotations[annotation]
+	})
+}
+
+// parseRateLimitValue parses a limit of the form requests/unit, e.g. 100/minute.
+func parseRateLimitValue(value string) (envoyv1.RateLimitValue, error) {
+	requests, unit, found := strings.Cut(strings.TrimSpace(value), "/")
+	count, err := strconv.ParseUint(strings.TrimSpace(requests), 10, 32)
+	if !found || err != nil || count == 0 {
+		return envoyv1.RateLimitValue{}, fmt.Errorf("invalid rate limit %q. Expected requests/unit, e.g. 100/minute", value)
+	}
+
+	units := []envoyv1.RateLimitUnit{envoyv1.RateLimitUnitSecond, envoyv1.RateLimitUnitMinute, envoyv1.RateLimitUnitHour, envoyv1.RateLimitUnitDay}
+	index := slices.IndexFunc(units, func(u envoyv1.RateLimitUnit) bool {
+		return strings.EqualFold(string(u), strings.TrimSpace(unit))
+	})
+	if index < 0 {
+		return envoyv1.RateLimitValue{}, fmt.Errorf("invalid rate limit unit %q. Valid values: second || minute || hour || day", unit)
+	}
+
+	return envoyv1.RateLimitValue{Requests: uint(count), Unit: units[index]}, nil
+}
+
+// parseRateLimitTiersAnnotation parses the rate-limit-tiers annotation, comma
+// separated list=requests/unit entries. Lists take the same forms as the lists annotation.
+func parseRateLimitTiersAnnotation(annotations map[string]string, localNamespace string) ([]rateLimitTier, error) {
+	var tiers []rateLimitTier
+	for _, entry := range annotationEntries(annotations, AnnotationSecurityPolicyRateLimitTiers) {
+		list, value, found := strings.Cut(entry, "=")
+		list = strings.TrimSpace(list)
+		if !found || list == "" {
+			return nil, fmt.Errorf("%s not valid: %q. Expected list=requests/unit", AnnotationSecurityPolicyRateLimitTiers, entry)
+		}
+		if _, err := parseListReference(list, localNamespace); err != nil {
+			return nil, fmt.Errorf("%s not valid: %w", AnnotationSecurityPolicyRateLimitTiers, err)
+		}
+		if slices.ContainsFunc(tiers, func(tier rateLimitTier) bool { return tier.list == list }) {
+			return nil, fmt.Errorf("%s not valid: list %q is used more than once", AnnotationSecurityPolicyRateLimitTiers, list)
+		}
+		limit, err := parseRateLimitValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s not valid: %w", AnnotationSecurityPolicyRateLimitTiers, err)
+		}
+		tiers = append(tiers, rateLimitTier{list: list, limit: limit})
+	}
+	return tiers, nil
+}
+
+// rateLimitTierLists returns the lists of the rate-limit-tiers annotation.
+// Invalid entries are skipped.
+func rateLimitTierLists(annotations map[string]string) []string {
+	var lists []string
+	for _, entry := range annotationEntries(annotations, AnnotationSecurityPolicyRateLimitTiers) {
+		if list, _, found := strings.Cut(entry, "="); found && strings.TrimSpace(list) != "" {
+			lists = append(lists, strings.TrimSpace(list))
+		}
+	}
+	return lists
+}
+
+// getRateLimit returns the rate limit described by the rate-limit annotations, or nil
+// if none is set. Each tier limits the clients in the CIDRs of its list, a client in
+// several lists gets the limit of the first tier. The rate-limit annotation limits
+// all other clients. Every client IP has its own counter.
+//
+// A request matching several rules is limited by the strictest of them. If no tier
+// is more generous than rate-limit, other clients are matched by a catch-all rule.
+// Otherwise they are matched by the CIDRs not covered by any tier, which takes many
+// rules and therefore requires a global rate limit.
+func getRateLimit(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, annotations map[string]string) (*envoyv1.RateLimitSpec, error) {
+	if !hasRateLimitAnnotations(annotations) {
+		return nil, nil
+	}
+
+	rateLimitType := RateLimitTypeLocal
+	if value, ok := annotations[AnnotationSecurityPolicyRateLimitType]; ok {
+		switch value {
+		case RateLimitTypeLocal, RateLimitTypeGlobal:
+			rateLimitType = value
+		default:
+			return nil, fmt.Errorf("%s not valid: %q. Valid values: %s || %s", AnnotationSecurityPolicyRateLimitType, value, RateLimitTypeLocal, RateLimitTypeGlobal)
+		}
+	}
+
+	tiers, err := parseRateLimitTiersAnnotation(annotations, gatewayApiResource.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaultLimit *envoyv1.RateLimitValue
+	if value, ok := annotations[AnnotationSecurityPolicyRateLimit]; ok {
+		limit, err := parseRateLimitValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s not valid: %w", AnnotationSecurityPolicyRateLimit, err)
+		}
+		defaultLimit = &limit
+	}
+
+	// Other clients can only be matched by a catch-all rule if it does not lower
+	// the limit of any tier
+	catchAll := defaultLimit != nil && !slices.ContainsFunc(tiers, func(tier rateLimitTier) bool {
+		return !rateLimitStricterOrEqual(tier.limit, *defaultLimit)
+	})
+	if defaultLimit != nil && !catchAll && rateLimitType != RateLimitTypeGlobal {
+		return nil, fmt.Errorf("%s above %s require %s: %s, since other clients are then matched by the CIDRs outside all tiers", AnnotationSecurityPolicyRateLimitTiers, AnnotationSecurityPolicyRateLimit, AnnotationSecurityPolicyRateLimitType, RateLimitTypeGlobal)
+	}
+
+	var rules []envoyv1.RateLimitRule
+	covered := &utils.PrefixSet{}
+	for _, tier := range tiers {
+		sources, err := getAddresses(ctx, r, gatewayApiResource, "", []string{tier.list}, nil, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		tierCIDRs, err := utils.NewPrefixSet(sources[0].CIDRs...)
+		if err != nil {
+			return nil, err
+		}
+		tierCIDRs = tierCIDRs.Subtract(covered)
+		covered = covered.Union(tierCIDRs)
+		rules = append(rules, rateLimitRules(tierCIDRs.Strings(), tier.limit)...)
+	}
+
+	if defaultLimit != nil {
+		others, err := utils.NewPrefixSet("0.0.0.0/0", "::/0")
+		if err != nil {
+			return nil, err
+		}
+		if !catchAll {
+			others = others.Subtract(covered)
+		}
+		rules = append(rules, rateLimitRules(others.Strings(), *defaultLimit)...)
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no CIDRs found in %s and %s is not set", AnnotationSecurityPolicyRateLimitTiers, AnnotationSecurityPolicyRateLimit)
+	}
+	if maxRules := maxRateLimitRules[rateLimitType]; len(rules) > maxRules {
+		return nil, fmt.Errorf("rate limit needs %d rules, a %s rate limit supports at most %d. Use fewer or larger CIDRs", len(rules), rateLimitType, maxRules)
+	}
+
+	if rateLimitType == RateLimitTypeGlobal {
+		return &envoyv1.RateLimitSpec{Global: &envoyv1.GlobalRateLimit{Rules: rules}}, nil
+	}
+	return &envoyv1.RateLimitSpec{Local: &envoyv1.LocalRateLimit{Rules: rules}}, nil
+}
+
+// rateLimitUnitSeconds are the lengths of the rate limit units.
+var rateLimitUnitSeconds = map[envoyv1.RateLimitUnit]uint64{
+	envoyv1.RateLimitUnitSecond: 1,
+	envoyv1.RateLimitUnitMinute: 60,
+	envoyv1.RateLimitUnitHour:   3600,
+	envoyv1.RateLimitUnitDay:    86400,
+}
+
+// rateLimitStricterOrEqual reports whether a allows at most as many requests per second as b.
+func rateLimitStricterOrEqual(a envoyv1.RateLimitValue, b envoyv1.RateLimitValue) bool {
+	return uint64(a.Requests)*rateLimitUnitSeconds[b.Unit] <= uint64(b.Requests)*rateLimitUnitSeconds[a.Unit]
+}
+
+// rateLimitRules returns a rule with the given limit per CIDR, counting each client IP separately.
+func rateLimitRules(cidrs []string, limit envoyv1.RateLimitValue) []envoyv1.RateLimitRule {
+	var rules []envoyv1.RateLimitRule
+	for _, cidr := range cidrs {
+		distinct := envoyv1.SourceMatchDistinct
+		rules = append(rules, envoyv1.RateLimitRule{
+			ClientSelectors: []envoyv1.RateLimitSelectCondition{
+				{SourceCIDR: &envoyv1.SourceMatch{Type: &distinct, Value: cidr}},
+			},
+			Limit: limit,
+		})
+	}
+	return rules
+}
+
+// reconcileBackendTrafficPolicy creates or updates the BackendTrafficPolicy of a route
+// or gateway from its rate-limit annotations, or deletes it if none is set. The
+// BackendTrafficPolicy has the same name as the SecurityPolicy of the route or gateway
+// and is marked with the managed-by annotation. A BackendTrafficPolicy that is not
+// marked, or another BackendTrafficPolicy targeting the route or gateway, is a conflict.
+func reconcileBackendTrafficPolicy(ctx context.Context, r client.Client, gatewayApiResource gatewayApiResource, annotations map[string]string) error {
+	if !hasRateLimitAnnotations(annotations) {
+		return deleteBackendTrafficPolicy(ctx, r, gatewayApiResource)
+	}
+
+	rateLimit, err := getRateLimit(ctx, r, gatewayApiResource, annotations)
+	if err != nil {
+		return err
+	}
+
+	targetRefs := []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+		{
+			LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+				Group: gatewayv1.Group(GatewayAPIGroup),
+				Kind:  gatewayv1.Kind(gatewayApiResource.Kind),
+				Name:  gatewayv1.ObjectName(gatewayApiResource.Name),
+			},
+		},
+	}
+
+	// Envoy Gateway applies only the oldest BackendTrafficPolicy targeting a route or gateway
+	backendTrafficPolicyList := &envoyv1.BackendTrafficPolicyList{}
+	if err := r.List(ctx, backendTrafficPolicyList, client.InNamespace(gatewayApiResource.Namespace)); err != nil {
+		return err
+	}
+	for _, other := range backendTrafficPolicyList.Items {
+		if other.Name == gatewayApiResource.securityPolicyName() {
+			continue
+		}
+		if slices.ContainsFunc(other.Spec.TargetRefs, gatewayApiResource.targetedBy) {
+			return fmt.Errorf("BackendTrafficPolicy %s/%s already targets %s %s", other.Namespace, other.Name, gatewayApiResource.Kind, gatewayApiResource.Name)
+		}
+	}
+
+	var backendTrafficPolicy envoyv1.BackendTrafficPolicy
+	err = r.Get(ctx, client.ObjectKey{Name: gatewayApiResource.securityPolicyName(), Namespace: gatewayApiResource.Namespace}, &backendTrafficPolicy)
+	if apierrors.IsNotFound(err) {
+		backendTrafficPolicy = envoyv1.BackendTrafficPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        gatewayApiResource.securityPolicyName(),
+				Namespace:   gatewayApiResource.Namespace,
+				Annotations: map[string]string{AnnotationSecurityPolicyManagedBy: SecurityPolicyOwner},
+			},
+			Spec: envoyv1.BackendTrafficPolicySpec{
+				PolicyTargetReferences: envoyv1.PolicyTargetReferences{TargetRefs: targetRefs},
+				RateLimit:              rateLimit,
+			},
+		}
+		if err := r.Create(ctx, &backendTrafficPolicy); err != nil {
+			return fmt.Errorf("failed to create BackendTrafficPolicy: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !managedByOperator(&backendTrafficPolicy) {
+		return fmt.Errorf("BackendTrafficPolicy %s/%s already exists and is not managed by %s", backendTrafficPolicy.Namespace, backendTrafficPolicy.Name, SecurityPolicyOwner)
+	}
+
+	backendTrafficPolicy.Spec.TargetRefs = targetRefs
+	backendTrafficPolicy.Spec.RateLimit = rateLimit
+	if err := r.Update(ctx, &backendTrafficPolicy); err != nil {
+		return fmt.Errorf("failed to update BackendTrafficPolicy: %w", err)
+	}
+
+	return nil
+}
+
+// deleteBackendTrafficPolicy deletes the BackendTrafficPolicy of a route or gateway if it
+// exists and is managed by the operator.
+func deleteBackendTrafficPolicy(ctx context.Context, r client.Client, gatewayApiResource gatewayApiResource) error {
+	backendTrafficPolicy := envoyv1.BackendTrafficPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gatewayApiResource.securityPolicyName(),
+			Namespace: gatewayApiResource.Namespace,
+		},
+	}
+	return deleteManagedObject(ctx, r, &backendTrafficPolicy)
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestParseRateLimitValue(t *testing.T) {
+	limit, err := parseRateLimitValue("100/minute")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit.Requests != 100 || limit.Unit != envoyv1.RateLimitUnitMinute {
+		t.Errorf("got %+v", limit)
+	}
+
+	for _, value := range []string{"100", "0/second", "-1/second", "100/week", "/hour"} {
+		if _, err := parseRateLimitValue(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestGetRateLimit(t *testing.T) {
+	ctx := context.Background()
+	route := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "api"}
+	r := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "partners"},
+			Data:       map[string]string{"cidrs": "192.0.2.0/24"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "office"},
+			Data:       map[string]string{"cidrs": "192.0.2.0/25,198.51.100.0/24"},
+		},
+	).Build()
+
+	rateLimit, err := getRateLimit(ctx, r, route, map[string]string{
+		AnnotationSecurityPolicyRateLimitTiers: "configmap:local:partners=1000/minute,configmap:local:office=500/minute",
+		AnnotationSecurityPolicyRateLimit:      "100/minute",
+		AnnotationSecurityPolicyRateLimitType:  "global",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rateLimit.Local != nil || rateLimit.Global == nil {
+		t.Fatalf("got %+v", rateLimit)
+	}
+
+	// The office CIDRs covered by partners keep the partner limit
+	rules := rateLimit.Global.Rules
+	limits := map[string]uint{}
+	for _, rule := range rules {
+		limits[rule.ClientSelectors[0].SourceCIDR.Value] = rule.Limit.Requests
+	}
+	if limits["192.0.2.0/24"] != 1000 || limits["198.51.100.0/24"] != 500 || limits["::/0"] != 100 {
+		t.Errorf("got limits %v", limits)
+	}
+	if _, ok := limits["192.0.2.0/25"]; ok {
+		t.Errorf("expected 192.0.2.0/25 to be covered by partners, got %v", limits)
+	}
+	for cidr, requests := range limits {
+		if requests == 100 && (cidr == "192.0.2.0/24" || cidr == "0.0.0.0/0") {
+			t.Errorf("expected other clients to exclude the tiers, got %v", limits)
+		}
+	}
+
+	// Without tiers, the limit applies to all clients
+	rateLimit, err = getRateLimit(ctx, r, route, map[string]string{AnnotationSecurityPolicyRateLimit: "100/minute"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules := rateLimit.Local.Rules; len(rules) != 2 || rules[0].ClientSelectors[0].SourceCIDR.Value != "0.0.0.0/0" {
+		t.Errorf("got %+v", rateLimit.Local)
+	}
+
+	// A local rate limit with stricter tiers matches other clients with a catch-all rule
+	rateLimit, err = getRateLimit(ctx, r, route, map[string]string{
+		AnnotationSecurityPolicyRateLimitTiers: "configmap:local:partners=10/minute,configmap:local:office=1/second",
+		AnnotationSecurityPolicyRateLimit:      "100/minute",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rateLimit.Local == nil || rateLimit.Global != nil {
+		t.Fatalf("got %+v", rateLimit)
+	}
+	limits = map[string]uint{}
+	for _, rule := range rateLimit.Local.Rules {
+		limits[rule.ClientSelectors[0].SourceCIDR.Value] = rule.Limit.Requests
+	}
+	if len(limits) != 4 || limits["192.0.2.0/24"] != 10 || limits["198.51.100.0/24"] != 1 || limits["0.0.0.0/0"] != 100 || limits["::/0"] != 100 {
+		t.Errorf("got limits %v", limits)
+	}
+
+	for _, annotations := range []map[string]string{
+		{AnnotationSecurityPolicyRateLimitTiers: "configmap:local:partners=1000/minute", AnnotationSecurityPolicyRateLimit: "100/minute"},
+		{AnnotationSecurityPolicyRateLimitTiers: "configmap:local:partners"},
+		{AnnotationSecurityPolicyRateLimitTiers: "configmap:local:partners=1/second,configmap:local:partners=2/second"},
+		{AnnotationSecurityPolicyRateLimitTiers: "configmap:local:missing=1/second"},
+		{AnnotationSecurityPolicyRateLimitTiers: "configmap:local:partners=1/second", AnnotationSecurityPolicyRateLimitType: "cluster"},
+		{AnnotationSecurityPolicyRateLimitType: "local"},
+	} {
+		if _, err := getRateLimit(ctx, r, route, annotations); err == nil {
+			t.Errorf("expected error for %v", annotations)
+		}
+	}
+}
+
+func TestReconcileBackendTrafficPolicy(t *testing.T) {
+	ctx := context.Background()
+	route := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "api"}
+	other := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "web"}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := envoyv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	r := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		// Created by someone else with the name the operator would use for web
+		&envoyv1.BackendTrafficPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: other.securityPolicyName()},
+		},
+	).Build()
+	annotations := map[string]string{AnnotationSecurityPolicyRateLimit: "100/minute"}
+	key := client.ObjectKey{Namespace: "default", Name: route.securityPolicyName()}
+
+	if err := reconcileBackendTrafficPolicy(ctx, r, route, annotations); err != nil {
+		t.Fatal(err)
+	}
+	var backendTrafficPolicy envoyv1.BackendTrafficPolicy
+	if err := r.Get(ctx, key, &backendTrafficPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if !managedByOperator(&backendTrafficPolicy) || backendTrafficPolicy.Spec.TargetRefs[0].Name != "api" {
+		t.Errorf("got %+v", backendTrafficPolicy)
+	}
+
+	// A BackendTrafficPolicy that is not managed by the operator is neither updated nor deleted
+	if err := reconcileBackendTrafficPolicy(ctx, r, other, annotations); err == nil {
+		t.Error("expected error for a BackendTrafficPolicy not managed by the operator")
+	}
+	if err := reconcileBackendTrafficPolicy(ctx, r, other, map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: other.securityPolicyName()}, &envoyv1.BackendTrafficPolicy{}); err != nil {
+		t.Errorf("expected BackendTrafficPolicy to be kept, got %v", err)
+	}
+
+	// Another BackendTrafficPolicy targeting the route is a conflict
+	conflicting := &envoyv1.BackendTrafficPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-limits"},
+		Spec: envoyv1.BackendTrafficPolicySpec{
+			PolicyTargetReferences: envoyv1.PolicyTargetReferences{
+				TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+					{LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{Group: GatewayAPIGroup, Kind: "HTTPRoute", Name: "api"}},
+				},
+			},
+		},
+	}
+	if err := r.Create(ctx, conflicting); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileBackendTrafficPolicy(ctx, r, route, annotations); err == nil {
+		t.Error("expected error for another BackendTrafficPolicy targeting the route")
+	}
+
+	if err := reconcileBackendTrafficPolicy(ctx, r, route, map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &backendTrafficPolicy); !apierrors.IsNotFound(err) {
+		t.Errorf("expected BackendTrafficPolicy to be deleted, got %v", err)
+	}
+}
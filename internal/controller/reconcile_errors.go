@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+)
+
+// splitErrors returns the errors joined in err, with nested joins flattened.
+func splitErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, err := range joined.Unwrap() {
+		errs = append(errs, splitErrors(err)...)
+	}
+	return errs
+}
+
+// isTransientError reports whether err may succeed on retry, such as a conflict or
+// an unavailable API server. Errors in the annotations, denied references and
+// missing or invalid objects are permanent: the route or gateway is reconciled again
+// when it or the referenced object changes.
+func isTransientError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		return false
+	}
+	return !apierrors.IsNotFound(err) && !apierrors.IsInvalid(err) && !apierrors.IsBadRequest(err)
+}
+
+// handleReconcileErrors records a warning event on object for every permanent error
+// in err and returns the transient errors, which are retried with backoff. Permanent
+// errors are not returned, as retrying them would fail the same way.
+func handleReconcileErrors(recorder events.EventRecorder, object runtime.Object, err error) error {
+	var transient []error
+	for _, err := range splitErrors(err) {
+		if isTransientError(err) {
+			transient = append(transient, err)
+			continue
+		}
+		if recorder == nil {
+			continue
+		}
+		reason := EventReasonInvalidConfiguration
+		var notPermitted *referenceNotPermittedError
+		if errors.As(err, &notPermitted) {
+			reason = EventReasonReferenceNotPermitted
+		}
+		recorder.Eventf(object, nil, corev1.EventTypeWarning, reason, "Reconcile", "%s", err.Error())
+	}
+	return errors.Join(transient...)
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/events"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestIsTransientError(t *testing.T) {
+	resource := schema.GroupResource{Group: "gateway.envoyproxy.io", Resource: "securitypolicies"}
+	for _, test := range []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("invalid CIDR"), false},
+		{&referenceNotPermittedError{}, false},
+		{fmt.Errorf("list: %w", apierrors.NewNotFound(resource, "office")), false},
+		{apierrors.NewInvalid(schema.GroupKind{Kind: "SecurityPolicy"}, "route", nil), false},
+		{apierrors.NewBadRequest("bad"), false},
+		{fmt.Errorf("update: %w", apierrors.NewConflict(resource, "route", errors.New("modified"))), true},
+		{apierrors.NewServiceUnavailable("unavailable"), true},
+		{apierrors.NewTooManyRequests("slow down", 1), true},
+		{fmt.Errorf("list: %w", context.DeadlineExceeded), true},
+	} {
+		if got := isTransientError(test.err); got != test.want {
+			t.Errorf("isTransientError(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+}
+
+func TestHandleReconcileErrors(t *testing.T) {
+	resource := schema.GroupResource{Group: "gateway.envoyproxy.io", Resource: "securitypolicies"}
+	conflict := apierrors.NewConflict(resource, "route", errors.New("modified"))
+	recorder := events.NewFakeRecorder(10)
+
+	err := handleReconcileErrors(recorder, &gatewayv1.HTTPRoute{}, errors.Join(
+		errors.New("invalid CIDR"),
+		errors.Join(&referenceNotPermittedError{}, conflict),
+	))
+	if !errors.Is(err, conflict) || len(splitErrors(err)) != 1 {
+		t.Errorf("expected only the conflict to be retried, got %v", err)
+	}
+
+	var reported []string
+	for len(recorder.Events) > 0 {
+		reported = append(reported, <-recorder.Events)
+	}
+	if len(reported) != 2 ||
+		!strings.Contains(reported[0], EventReasonInvalidConfiguration) ||
+		!strings.Contains(reported[1], EventReasonReferenceNotPermitted) {
+		t.Errorf("got events %q", reported)
+	}
+
+	if err := handleReconcileErrors(recorder, &gatewayv1.HTTPRoute{}, nil); err != nil || len(recorder.Events) != 0 {
+		t.Errorf("expected nothing for no errors, got %v", err)
+	}
+}
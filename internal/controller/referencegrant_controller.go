@@ -0,0 +1,94 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// ReferenceGrantReconciler reconciles a ReferenceGrant object
+type ReferenceGrantReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
+
+// Reconcile triggers reconciliation of all routes and gateways in other namespaces
+// that reference a list or Service in the namespace of the ReferenceGrant, so granted
+// references are resolved and revoked references are removed. Deleted ReferenceGrants
+// are reconciled as well.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
+func (r *ReferenceGrantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	log.Info("Reconciling ReferenceGrant", "ReferenceGrant.Namespace", req.Namespace, "ReferenceGrant.Name", req.Name)
+
+	// Find all HttpRoutes, GRPCRoutes and Gateways that reference the namespace of the ReferenceGrant
+	consumers, err := referenceGrantConsumers(ctx, r.Client, req.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to list consumers of ReferenceGrant")
+		return ctrl.Result{}, err
+	}
+
+	// Update each consumer to trigger reconciliation
+	for _, consumer := range consumers {
+		if err := notifyController(ctx, r.Client, consumer.Object); err != nil {
+			log.Error(err, "Failed to notify "+consumer.Kind, consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+			return ctrl.Result{}, err
+		}
+		log.Info("Patched "+consumer.Kind+" due to ReferenceGrant change", consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReferenceGrantReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	started := time.Now().Truncate(time.Second)
+
+	// Predicate that filters updates where the spec did not change, and ReferenceGrants
+	// replayed on startup, since routes and gateways are reconciled on startup anyway
+	specChangedPredicate := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return !e.Object.GetCreationTimestamp().Time.Before(started)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gatewayv1beta1.ReferenceGrant{}).
+		Named("referencegrant").
+		WithEventFilter(specChangedPredicate).
+		Complete(r)
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+)
+
+// resolveAddressToken returns the CIDRs a reserved token currently resolves to:
+// the internal and external addresses of all Nodes for "@nodes", the pod CIDRs
+// of all Nodes for "@pod-cidrs" and the LoadBalancer ingress IPs of the Service
+// for "@service:".
+func resolveAddressToken(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, token addressToken) ([]string, error) {
+
+	var cidrs []string
+
+	switch token.Name {
+	case AddressTokenNodes, AddressTokenPodCIDRs:
+		var nodeList corev1.NodeList
+		if err := r.List(ctx, &nodeList); err != nil {
+			return nil, fmt.Errorf("unable to list Nodes for %q: %w", token.Name, err)
+		}
+
+		for _, node := range nodeList.Items {
+			if token.Name == AddressTokenPodCIDRs {
+				podCIDRs := node.Spec.PodCIDRs
+				if len(podCIDRs) == 0 && node.Spec.PodCIDR != "" {
+					podCIDRs = []string{node.Spec.PodCIDR}
+				}
+				for _, podCIDR := range podCIDRs {
+					if utils.CheckValidCIDR(podCIDR) {
+						cidrs = append(cidrs, podCIDR)
+					}
+				}
+				continue
+			}
+
+			for _, address := range node.Status.Addresses {
+				if address.Type == corev1.NodeInternalIP || address.Type == corev1.NodeExternalIP {
+					cidrs = appendHostCIDR(cidrs, address.Address)
+				}
+			}
+		}
+
+	default:
+		// Cross-namespace references must be permitted by a ReferenceGrant in the Service's namespace
+		if err := checkReferencePermitted(ctx, r, gatewayApiResource, token.Service); err != nil {
+			return nil, err
+		}
+
+		service := corev1.Service{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: token.Service.Namespace, Name: token.Service.Name}, &service); err != nil {
+			return nil, fmt.Errorf("unable to fetch Service %q: %w", token.Service, err)
+		}
+
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			cidrs = appendHostCIDR(cidrs, ingress.IP)
+		}
+	}
+
+	return cidrs, nil
+}
+
+// appendHostCIDR appends the /32 or /128 CIDR of ip, ignoring invalid or empty addresses.
+func appendHostCIDR(cidrs []string, ip string) []string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return cidrs
+	}
+	addr = addr.Unmap()
+	return append(cidrs, netip.PrefixFrom(addr, addr.BitLen()).String())
+}
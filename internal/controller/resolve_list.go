@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	securitypoliciesv1alpha1 "github.com/vitistack/gatewayapi-securitypolicy-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolveList fetches the referenced list and returns its CIDRs.
+func resolveList(ctx context.Context, r Client, gatewayApiResource gatewayApiResource, reference listReference) ([]string, error) {
+
+	// Cross-namespace references must be permitted by a ReferenceGrant in the list's namespace
+	if err := checkReferencePermitted(ctx, r, gatewayApiResource, reference); err != nil {
+		return nil, err
+	}
+
+	switch reference.Kind {
+	case AddressListKind:
+		addressList := securitypoliciesv1alpha1.AddressList{}
+		if err := r.Get(ctx, client.ObjectKey{Name: reference.Name}, &addressList); err != nil {
+			return nil, fmt.Errorf("unable to fetch AddressList %q: %w", reference.Name, err)
+		}
+
+		// Extract CIDRs from AddressList
+		return extractCIDRsFromAddressList(&addressList, nil)
+
+	case ConfigMapKind:
+		configMap := corev1.ConfigMap{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: reference.Namespace, Name: reference.Name}, &configMap); err != nil {
+			return nil, fmt.Errorf("unable to fetch ConfigMap %q: %w", reference, err)
+		}
+
+		// Extract CIDRs from ConfigMap
+		return extractCIDRsFromConfigMap(&configMap, nil)
+
+	default:
+		networkPolicy := v1.NetworkPolicy{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: reference.Namespace, Name: reference.Name}, &networkPolicy); err != nil {
+			return nil, fmt.Errorf("unable to fetch NetworkPolicy %q: %w", reference, err)
+		}
+
+		// Extract CIDRs from NetworkPolicy
+		return extractCIDRsFromNetworkPolicy(&networkPolicy, nil)
+	}
+}
@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"golang.org/x/net/http/httpguts"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+)
+
+// rulesDocument is the value of the rules annotation, a JSON or YAML document.
+type rulesDocument struct {
+	Version string       `json:"version"`
+	Rules   []ruleConfig `json:"rules"`
+}
+
+// ruleConfig describes a single authorization rule. Lists, addresses and the
+// expression take the same forms as the lists, addresses and expression annotations,
+// and their CIDRs are combined into the client CIDRs of the rule.
+// All conditions of a rule must match for the rule to apply.
+type ruleConfig struct {
+	Name       string             `json:"name,omitempty"`
+	Action     string             `json:"action"`
+	Lists      []string           `json:"lists,omitempty"`
+	Addresses  []string           `json:"addresses,omitempty"`
+	Expression string             `json:"expression,omitempty"`
+	Methods    []string           `json:"methods,omitempty"`
+	Headers    []ruleHeaderConfig `json:"headers,omitempty"`
+	JWT        *ruleJWTConfig     `json:"jwt,omitempty"`
+}
+
+// ruleHeaderConfig matches a request header against a set of exact values.
+type ruleHeaderConfig struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// ruleJWTConfig matches the claims and scopes of the JWT verified by the JWT provider
+// of the jwt- annotations.
+type ruleJWTConfig struct {
+	Claims []ruleJWTClaimConfig `json:"claims,omitempty"`
+	Scopes []string             `json:"scopes,omitempty"`
+}
+
+// ruleJWTClaimConfig matches a JWT claim against a set of values. ValueType is
+// String or StringArray, it defaults to String.
+type ruleJWTClaimConfig struct {
+	Name      string   `json:"name"`
+	ValueType string   `json:"valueType,omitempty"`
+	Values    []string `json:"values"`
+}
+
+// hasClientCIDRs reports whether the rule matches on client CIDRs.
+func (c ruleConfig) hasClientCIDRs() bool {
+	return len(c.Lists) > 0 || len(c.Addresses) > 0 || strings.TrimSpace(c.Expression) != ""
+}
+
+// supportedRuleActions are the valid actions of a rule.
+var supportedRuleActions = []string{"allow", "deny"}
+
+// supportedRuleMethods are the HTTP methods a rule can match on.
+var supportedRuleMethods = []string{"GET", "HEAD", "POST", "PUT", "DELETE", "CONNECT", "OPTIONS", "TRACE", "PATCH"}
+
+// supportedJWTClaimValueTypes are the valid value types of a JWT claim.
+var supportedJWTClaimValueTypes = []string{string(envoyv1.JWTClaimValueTypeString), string(envoyv1.JWTClaimValueTypeStringArray)}
+
+// parseRulesAnnotation parses and validates the rules annotation of a resource in
+// localNamespace. Validation errors report the path of the offending field,
+// e.g. rules[1].methods[0].
+func parseRulesAnnotation(annotation string, localNamespace string) (rulesDocument, error) {
+	var document rulesDocument
+	if err := yaml.UnmarshalStrict([]byte(annotation), &document); err != nil {
+		return rulesDocument{}, fmt.Errorf("unable to parse rules: %w", err)
+	}
+
+	if errs := validateRulesDocument(document, localNamespace); len(errs) > 0 {
+		return rulesDocument{}, errs.ToAggregate()
+	}
+
+	return document, nil
+}
+
+// validateRulesDocument validates a rules document against the schema of its version.
+func validateRulesDocument(document rulesDocument, localNamespace string) field.ErrorList {
+	var errs field.ErrorList
+
+	switch document.Version {
+	case "":
+		errs = append(errs, field.Required(field.NewPath("version"), ""))
+	case RulesVersionV1:
+	default:
+		return append(errs, field.NotSupported(field.NewPath("version"), document.Version, []string{RulesVersionV1}))
+	}
+
+	rulesPath := field.NewPath("rules")
+	if len(document.Rules) == 0 {
+		errs = append(errs, field.Required(rulesPath, "at least one rule must be specified"))
+	}
+
+	names := sets.New[string]()
+	for i, rule := range document.Rules {
+		rulePath := rulesPath.Index(i)
+
+		if rule.Name != "" {
+			if len(rule.Name) > RuleNameMaxLength {
+				errs = append(errs, field.TooLong(rulePath.Child("name"), rule.Name, RuleNameMaxLength))
+			}
+			if isGeneratedRuleName(rule.Name) {
+				errs = append(errs, field.Invalid(rulePath.Child("name"), rule.Name, "names of the form list-*, addresses and expression, optionally prefixed with allow- or deny-, are reserved for the rules of the shorthand annotations"))
+			}
+			if names.Has(rule.Name) {
+				errs = append(errs, field.Duplicate(rulePath.Child("name"), rule.Name))
+			}
+			names.Insert(rule.Name)
+		}
+
+		switch rule.Action {
+		case "":
+			errs = append(errs, field.Required(rulePath.Child("action"), ""))
+		case "allow", "deny":
+		default:
+			errs = append(errs, field.NotSupported(rulePath.Child("action"), rule.Action, supportedRuleActions))
+		}
+
+		for j, list := range rule.Lists {
+			if _, err := parseListReference(list, localNamespace); err != nil {
+				errs = append(errs, field.Invalid(rulePath.Child("lists").Index(j), list, err.Error()))
+			}
+		}
+
+		for j, address := range rule.Addresses {
+			if err := validateAddressEntry(address, localNamespace); err != nil {
+				errs = append(errs, field.Invalid(rulePath.Child("addresses").Index(j), address, err.Error()))
+			}
+		}
+
+		if strings.TrimSpace(rule.Expression) != "" {
+			if _, err := utils.ParseExpression(rule.Expression); err != nil {
+				errs = append(errs, field.Invalid(rulePath.Child("expression"), rule.Expression, err.Error()))
+			}
+		}
+
+		if len(rule.Methods) > 16 {
+			errs = append(errs, field.TooMany(rulePath.Child("methods"), len(rule.Methods), 16))
+		}
+		for j, method := range rule.Methods {
+			if !sets.New(supportedRuleMethods...).Has(method) {
+				errs = append(errs, field.NotSupported(rulePath.Child("methods").Index(j), method, supportedRuleMethods))
+			}
+		}
+
+		for j, header := range rule.Headers {
+			headerPath := rulePath.Child("headers").Index(j)
+			if header.Name == "" {
+				errs = append(errs, field.Required(headerPath.Child("name"), ""))
+			} else if !httpguts.ValidHeaderFieldName(header.Name) {
+				errs = append(errs, field.Invalid(headerPath.Child("name"), header.Name, "invalid header name"))
+			}
+			if len(header.Values) == 0 {
+				errs = append(errs, field.Required(headerPath.Child("values"), "at least one value must be specified"))
+			}
+		}
+
+		if rule.JWT != nil {
+			jwtPath := rulePath.Child("jwt")
+			if len(rule.JWT.Claims) == 0 && len(rule.JWT.Scopes) == 0 {
+				errs = append(errs, field.Required(jwtPath, "at least one of claims or scopes must be specified"))
+			}
+			for j, claim := range rule.JWT.Claims {
+				claimPath := jwtPath.Child("claims").Index(j)
+				if claim.Name == "" {
+					errs = append(errs, field.Required(claimPath.Child("name"), ""))
+				}
+				switch envoyv1.JWTClaimValueType(claim.ValueType) {
+				case "", envoyv1.JWTClaimValueTypeString, envoyv1.JWTClaimValueTypeStringArray:
+				default:
+					errs = append(errs, field.NotSupported(claimPath.Child("valueType"), claim.ValueType, supportedJWTClaimValueTypes))
+				}
+				if len(claim.Values) == 0 {
+					errs = append(errs, field.Required(claimPath.Child("values"), "at least one value must be specified"))
+				}
+			}
+		}
+
+		if !rule.hasClientCIDRs() && len(rule.Headers) == 0 && rule.JWT == nil {
+			errs = append(errs, field.Required(rulePath, "at least one of lists, addresses, expression, headers or jwt must be specified"))
+		}
+	}
+
+	return errs
+}
+
+// validateAddressEntry checks that an entry of the addresses annotation is a CIDR,
+// a dns: entry or a reserved address token.
+func validateAddressEntry(entry string, localNamespace string) error {
+	if _, isToken, err := parseAddressToken(entry, localNamespace); isToken || err != nil {
+		return err
+	}
+	if _, isDNSEntry, err := parseDNSEntry(entry); isDNSEntry || err != nil {
+		return err
+	}
+	if !utils.CheckValidCIDR(entry) {
+		return fmt.Errorf("invalid CIDR")
+	}
+	return nil
+}
+
+// rulesAnnotationEntries returns the lists and addresses referenced by the rules
+// annotation, including the operands of the expressions. Invalid documents have no entries.
+func rulesAnnotationEntries(annotations map[string]string, localNamespace string) (lists []string, addresses []string) {
+	annotation, ok := annotations[AnnotationSecurityPolicyRules]
+	if !ok {
+		return nil, nil
+	}
+	document, err := parseRulesAnnotation(annotation, localNamespace)
+	if err != nil {
+		return nil, nil
+	}
+	for _, rule := range document.Rules {
+		operands := expressionOperands(rule.Expression)
+		lists = append(append(lists, rule.Lists...), operands...)
+		addresses = append(append(addresses, rule.Addresses...), operands...)
+	}
+	return lists, addresses
+}
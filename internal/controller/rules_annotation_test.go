@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRulesAnnotation(t *testing.T) {
+	document, err := parseRulesAnnotation(`
+version: v1
+rules:
+- name: office-write
+  action: allow
+  lists: [office]
+  methods: [POST, PUT]
+- action: deny
+  addresses: ["10.12.0.7/32", "dns:partner.example.com"]
+  headers:
+  - name: x-internal-caller
+    values: [billing]
+`, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(document.Rules) != 2 || document.Rules[0].Name != "office-write" || len(document.Rules[1].Headers) != 1 {
+		t.Errorf("got %+v", document)
+	}
+
+	// JSON is valid YAML
+	if _, err := parseRulesAnnotation(`{"version":"v1","rules":[{"action":"allow","expression":"office | vpn"}]}`, "default"); err != nil {
+		t.Errorf("unexpected error for JSON document: %v", err)
+	}
+}
+
+func TestParseRulesAnnotationErrors(t *testing.T) {
+	tests := []struct {
+		annotation string
+		want       string
+	}{
+		{`{"rules":[{"action":"allow","lists":["office"]}]}`, "version: Required value"},
+		{`{"version":"v2","rules":[]}`, `version: Unsupported value: "v2"`},
+		{`{"version":"v1","rules":[{"action":"permit","lists":["office"]}]}`, `rules[0].action: Unsupported value: "permit"`},
+		{`{"version":"v1","rules":[{"action":"allow","lists":["Office"]}]}`, `rules[0].lists[0]: Invalid value: "Office"`},
+		{`{"version":"v1","rules":[{"action":"allow","addresses":["10.0.0.0/33"]}]}`, `rules[0].addresses[0]: Invalid value: "10.0.0.0/33"`},
+		{`{"version":"v1","rules":[{"action":"allow","lists":["office"],"methods":["get"]}]}`, `rules[0].methods[0]: Unsupported value: "get"`},
+		{`{"version":"v1","rules":[{"action":"allow","headers":[{"name":"x-caller"}]}]}`, "rules[0].headers[0].values: Required value"},
+		{`{"version":"v1","rules":[{"action":"allow"}]}`, "rules[0]: Required value"},
+		{`{"version":"v1","rules":[{"name":"a","action":"allow","lists":["office"]},{"name":"a","action":"deny","lists":["vpn"]}]}`, `rules[1].name: Duplicate value: "a"`},
+		{`{"version":"v1","rules":[{"action":"allow","list":["office"]}]}`, `unknown field "list"`},
+		{`{"version":"v1","rules":[{"name":"addresses","action":"allow","lists":["office"]}]}`, `rules[0].name: Invalid value: "addresses"`},
+		{`{"version":"v1","rules":[{"name":"list-office","action":"allow","lists":["office"]}]}`, `rules[0].name: Invalid value: "list-office"`},
+		{`{"version":"v1","rules":[{"name":"deny-expression","action":"deny","lists":["office"]}]}`, `rules[0].name: Invalid value: "deny-expression"`},
+	}
+	for _, test := range tests {
+		_, err := parseRulesAnnotation(test.annotation, "default")
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("parseRulesAnnotation(%s) error = %v, want %q", test.annotation, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// SecretReconciler reconciles a Secret object
+type SecretReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+// Reconcile triggers reconciliation of all routes and gateways that reference the
+// Secret in their annotations, so changes are validated again. Deleted Secrets are
+// reconciled as well, since the SecurityPolicy can no longer use them.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
+func (r *SecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	log.Info("Reconciling Secret", "Secret.Namespace", req.Namespace, "Secret.Name", req.Name)
+
+	// Find all HttpRoutes, GRPCRoutes and Gateways that reference the Secret
+	consumers, err := secretConsumers(ctx, r.Client, req.Namespace, req.Name)
+	if err != nil {
+		log.Error(err, "Failed to list consumers of Secret")
+		return ctrl.Result{}, err
+	}
+
+	// Update each consumer to trigger reconciliation
+	for _, consumer := range consumers {
+		if err := notifyController(ctx, r.Client, consumer.Object); err != nil {
+			log.Error(err, "Failed to notify "+consumer.Kind, consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+			return ctrl.Result{}, err
+		}
+		log.Info("Patched "+consumer.Kind+" due to Secret change", consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// referencedSecretPredicate passes events of Secrets referenced by a route or gateway.
+// Secrets created before started are skipped, since routes and gateways are reconciled
+// on startup anyway. Updates only pass when the Secret changed.
+func referencedSecretPredicate(r Client, started time.Time) predicate.Funcs {
+	referenced := func(object client.Object) bool {
+		consumers, err := secretConsumers(context.Background(), r, object.GetNamespace(), object.GetName())
+		return err == nil && len(consumers) > 0
+	}
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetResourceVersion() != e.ObjectNew.GetResourceVersion() && referenced(e.ObjectNew)
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return !e.Object.GetCreationTimestamp().Time.Before(started) && referenced(e.Object)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return referenced(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	// Only the metadata of Secrets is cached, their data is read from the API server
+	// when a route or gateway is reconciled
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Secret{}, builder.OnlyMetadata).
+		Named("secret").
+		WithEventFilter(referencedSecretPredicate(r.Client, time.Now().Truncate(time.Second))).
+		Complete(r)
+}
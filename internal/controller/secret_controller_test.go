@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestReferencedSecretPredicate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayv1.Install(scheme); err != nil {
+		t.Fatal(err)
+	}
+	r := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "api",
+			Annotations: map[string]string{AnnotationSecurityPolicyBasicAuthSecret: "users"},
+		},
+	}).Build()
+	started := time.Now().Truncate(time.Second)
+	referencedSecret := referencedSecretPredicate(r, started)
+
+	secret := func(name string, created time.Time, resourceVersion string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			ResourceVersion:   resourceVersion,
+		}}
+	}
+	before := started.Add(-time.Hour)
+
+	// Secrets replayed on startup are skipped
+	if referencedSecret.Create(event.CreateEvent{Object: secret("users", before, "1")}) {
+		t.Error("expected Secret created before the start to be skipped")
+	}
+	if !referencedSecret.Create(event.CreateEvent{Object: secret("users", started, "1")}) {
+		t.Error("expected new referenced Secret to pass")
+	}
+	if referencedSecret.Create(event.CreateEvent{Object: secret("other", started, "1")}) {
+		t.Error("expected unreferenced Secret to be skipped")
+	}
+
+	if !referencedSecret.Update(event.UpdateEvent{ObjectOld: secret("users", before, "1"), ObjectNew: secret("users", before, "2")}) {
+		t.Error("expected changed referenced Secret to pass")
+	}
+	if referencedSecret.Update(event.UpdateEvent{ObjectOld: secret("users", before, "2"), ObjectNew: secret("users", before, "2")}) {
+		t.Error("expected resync of an unchanged Secret to be skipped")
+	}
+	if referencedSecret.Update(event.UpdateEvent{ObjectOld: secret("other", before, "1"), ObjectNew: secret("other", before, "2")}) {
+		t.Error("expected unreferenced Secret to be skipped")
+	}
+
+	if !referencedSecret.Delete(event.DeleteEvent{Object: secret("users", before, "2")}) {
+		t.Error("expected deleted referenced Secret to pass")
+	}
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+
+	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+)
+
+// authorizationAnnotations are the annotations that configure the authorization
+// rules of the SecurityPolicy of a route or gateway.
+var authorizationAnnotations = []string{
+	AnnotationSecurityPolicyDefaultAction,
+	AnnotationSecurityPolicyLists,
+	AnnotationSecurityPolicyAddresses,
+	AnnotationSecurityPolicyExpression,
+	AnnotationSecurityPolicyAllowLists,
+	AnnotationSecurityPolicyAllowAddresses,
+	AnnotationSecurityPolicyDenyLists,
+	AnnotationSecurityPolicyDenyAddresses,
+	AnnotationSecurityPolicyRuleMode,
+	AnnotationSecurityPolicyRules,
+	AnnotationSecurityPolicyMethods,
+	AnnotationSecurityPolicyAllowMethods,
+	AnnotationSecurityPolicyDenyMethods,
+	AnnotationSecurityPolicyHeaders,
+	AnnotationSecurityPolicyAllowHeaders,
+	AnnotationSecurityPolicyDenyHeaders,
+	AnnotationSecurityPolicyJWTClaims,
+	AnnotationSecurityPolicyJWTScopes,
+}
+
+// securityPolicyAnnotations are the annotations that configure the SecurityPolicy
+// of a route or gateway.
+var securityPolicyAnnotations = slices.Concat(authorizationAnnotations, []string{
+	AnnotationSecurityPolicyJWTIssuer,
+	AnnotationSecurityPolicyJWTJWKSURI,
+	AnnotationSecurityPolicyJWTAudiences,
+	AnnotationSecurityPolicyJWTOptional,
+	AnnotationSecurityPolicyBasicAuthSecret,
+	AnnotationSecurityPolicyBasicAuthForwardUsernameHeader,
+	AnnotationSecurityPolicyCORSAllowOrigins,
+	AnnotationSecurityPolicyCORSAllowMethods,
+	AnnotationSecurityPolicyCORSAllowHeaders,
+	AnnotationSecurityPolicyCORSExposeHeaders,
+	AnnotationSecurityPolicyCORSMaxAge,
+	AnnotationSecurityPolicyCORSAllowCredentials,
+	AnnotationSecurityPolicyExtAuthService,
+	AnnotationSecurityPolicyExtAuthPort,
+	AnnotationSecurityPolicyExtAuthProtocol,
+	AnnotationSecurityPolicyExtAuthPath,
+	AnnotationSecurityPolicyExtAuthHeaders,
+	AnnotationSecurityPolicyExtAuthHeadersToBackend,
+	AnnotationSecurityPolicyExtAuthFailOpen,
+	AnnotationSecurityPolicyOIDCIssuer,
+	AnnotationSecurityPolicyOIDCClientID,
+	AnnotationSecurityPolicyOIDCClientSecret,
+	AnnotationSecurityPolicyOIDCScopes,
+	AnnotationSecurityPolicyOIDCRedirectURL,
+	AnnotationSecurityPolicyAPIKeySecrets,
+	AnnotationSecurityPolicyAPIKeyHeaders,
+	AnnotationSecurityPolicyAPIKeyParams,
+	AnnotationSecurityPolicyAPIKeyForwardClientIDHeader,
+})
+
+// secretAnnotations are the annotations that reference a Secret in the namespace
+// of the route or gateway.
+var secretAnnotations = []string{
+	AnnotationSecurityPolicyBasicAuthSecret,
+	AnnotationSecurityPolicyOIDCClientSecret,
+	AnnotationSecurityPolicyAPIKeySecrets,
+}
+
+// serviceAnnotations are the annotations that reference a Service in the namespace
+// of the route or gateway.
+var serviceAnnotations = []string{
+	AnnotationSecurityPolicyExtAuthService,
+}
+
+// listAnnotations are the annotations that reference lists.
+var listAnnotations = []string{
+	AnnotationSecurityPolicyLists,
+	AnnotationSecurityPolicyAllowLists,
+	AnnotationSecurityPolicyDenyLists,
+	AnnotationSecurityPolicyClientIPTrustedLists,
+}
+
+// addressAnnotations are the annotations that contain addresses.
+var addressAnnotations = []string{
+	AnnotationSecurityPolicyAddresses,
+	AnnotationSecurityPolicyAllowAddresses,
+	AnnotationSecurityPolicyDenyAddresses,
+}
+
+// annotationEntries returns the comma separated entries of the given annotations.
+func annotationEntries(annotations map[string]string, keys ...string) []string {
+	var entries []string
+	for _, key := range keys {
+		if value, ok := annotations[key]; ok {
+			entries = append(entries, utils.FilterSliceFromString(strings.Split(value, ","))...)
+		}
+	}
+	return entries
+}
+
+// listEntries returns all list references of a route or gateway in localNamespace,
+// including the operands of its set expression, the lists of its rules annotation,
+// the lists of its rate limit tiers and the lists of its listeners.
+func listEntries(annotations map[string]string, localNamespace string) []string {
+	var entries []string
+	for _, set := range annotationSets(annotations) {
+		entries = append(entries, annotationEntries(set, listAnnotations...)...)
+		entries = append(entries, rateLimitTierLists(set)...)
+		entries = append(entries, expressionOperands(set[AnnotationSecurityPolicyExpression])...)
+		lists, _ := rulesAnnotationEntries(set, localNamespace)
+		entries = append(entries, lists...)
+	}
+	return entries
+}
+
+// addressEntries returns all addresses of a route or gateway in localNamespace,
+// including the operands of its set expression, the addresses of its rules annotation
+// and the addresses of its listeners.
+func addressEntries(annotations map[string]string, localNamespace string) []string {
+	var entries []string
+	for _, set := range annotationSets(annotations) {
+		entries = append(entries, annotationEntries(set, addressAnnotations...)...)
+		entries = append(entries, expressionOperands(set[AnnotationSecurityPolicyExpression])...)
+		_, addresses := rulesAnnotationEntries(set, localNamespace)
+		entries = append(entries, addresses...)
+	}
+	return entries
+}
+
+// hasSecurityPolicyAnnotations reports whether any annotation configuring the
+// SecurityPolicy is set.
+func hasSecurityPolicyAnnotations(annotations map[string]string) bool {
+	for _, annotation := range securityPolicyAnnotations {
+		if annotations[annotation] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAuthorizationAnnotations reports whether any annotation configuring the
+// authorization rules is set. Without them, the SecurityPolicy has no authorization,
+// e.g. when it only configures CORS.
+func hasAuthorizationAnnotations(annotations map[string]string) bool {
+	return slices.ContainsFunc(authorizationAnnotations, func(annotation string) bool {
+		return annotations[annotation] != ""
+	})
+}
+
+// securityPolicyAnnotationsChanged reports whether any annotation configuring the
+// SecurityPolicy differs between oldAnnotations and newAnnotations.
+func securityPolicyAnnotationsChanged(oldAnnotations map[string]string, newAnnotations map[string]string) bool {
+	for _, annotation := range securityPolicyAnnotations {
+		if oldAnnotations[annotation] != newAnnotations[annotation] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ServiceReconciler reconciles a Service object
+type ServiceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// The state of each Service when its consumers were last notified, and the time
+	// the controller was set up. Reconciles of a controller do not run concurrently.
+	services map[types.NamespacedName]serviceState
+	started  time.Time
+}
+
+// serviceState is what the consumers of a Service use: whether it exists, its
+// LoadBalancer ingress CIDRs and its ports.
+type serviceState struct {
+	exists bool
+	cidrs  []string
+	ports  []corev1.ServicePort
+}
+
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
+
+// Reconcile triggers reconciliation of all routes and gateways that use the
+// Service in a @service: token of their addresses annotation or as their external
+// authorization service. Deleted Services are reconciled as well, since their
+// addresses must be removed and the SecurityPolicy can no longer use them.
+// Consumers are only notified when the state of the Service changed. Services
+// created before the controller started are only recorded, since routes and
+// gateways are reconciled on startup anyway.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
+func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	log.Info("Reconciling Service", "Service.Namespace", req.Namespace, "Service.Name", req.Name)
+
+	// Skip notifying consumers if the Service did not change
+	var service corev1.Service
+	state := serviceState{}
+	if err := r.Get(ctx, req.NamespacedName, &service); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	} else if err == nil {
+		state.exists = true
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			state.cidrs = appendHostCIDR(state.cidrs, ingress.IP)
+		}
+		state.ports = service.Spec.Ports
+	}
+	if r.services == nil {
+		r.services = map[types.NamespacedName]serviceState{}
+	}
+	previous, known := r.services[req.NamespacedName]
+	if known && reflect.DeepEqual(previous, state) {
+		return ctrl.Result{}, nil
+	}
+	if !known && state.exists && service.CreationTimestamp.Time.Before(r.started) {
+		r.services[req.NamespacedName] = state
+		return ctrl.Result{}, nil
+	}
+
+	// Find all HttpRoutes, GRPCRoutes and Gateways that use the Service
+	consumers, err := addressTokenConsumers(ctx, r.Client, addressToken{
+		Name:    AddressTokenService,
+		Service: listReference{Kind: ServiceKind, Namespace: req.Namespace, Name: req.Name},
+	})
+	if err != nil {
+		log.Error(err, "Failed to list consumers of Service")
+		return ctrl.Result{}, err
+	}
+	extAuthConsumers, err := serviceConsumers(ctx, r.Client, req.Namespace, req.Name)
+	if err != nil {
+		log.Error(err, "Failed to list consumers of Service")
+		return ctrl.Result{}, err
+	}
+	for _, consumer := range extAuthConsumers {
+		if !slices.ContainsFunc(consumers, func(c listConsumer) bool { return c.gatewayApiResource == consumer.gatewayApiResource }) {
+			consumers = append(consumers, consumer)
+		}
+	}
+
+	// Update each consumer to trigger reconciliation
+	for _, consumer := range consumers {
+		if err := notifyController(ctx, r.Client, consumer.Object); err != nil {
+			log.Error(err, "Failed to notify "+consumer.Kind, consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+			return ctrl.Result{}, err
+		}
+		log.Info("Patched "+consumer.Kind+" due to Service change", consumer.Kind+".Namespace", consumer.Namespace, consumer.Kind+".Name", consumer.Name)
+	}
+	if state.exists {
+		r.services[req.NamespacedName] = state
+	} else {
+		delete(r.services, req.NamespacedName)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.started = time.Now().Truncate(time.Second)
+
+	// Predicate that filters updates where neither the LoadBalancer ingress nor the ports changed
+	ingressChangedPredicate := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldService, okOld := e.ObjectOld.(*corev1.Service)
+			newService, okNew := e.ObjectNew.(*corev1.Service)
+			if !okOld || !okNew {
+				return false
+			}
+			return !reflect.DeepEqual(oldService.Status.LoadBalancer.Ingress, newService.Status.LoadBalancer.Ingress) ||
+				!reflect.DeepEqual(oldService.Spec.Ports, newService.Spec.Ports)
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Service{}).
+		Named("service").
+		WithEventFilter(ingressChangedPredicate).
+		Complete(r)
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestServiceReconcileNotifiesOnChange(t *testing.T) {
+	ctx := context.Background()
+	route := &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "api",
+		Annotations: map[string]string{
+			AnnotationSecurityPolicyAddresses:   "@service:default/ingress",
+			AnnotationSecurityPolicyLastUpdated: "reconciled",
+		},
+	}}
+	started := time.Now().Truncate(time.Second)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ingress", CreationTimestamp: metav1.NewTime(started.Add(-time.Hour))},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "https", Port: 443}}},
+	}
+	r := newConsumerTestClient(t, route, service)
+	reconciler := &ServiceReconciler{Client: r, started: started}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "ingress"}}
+
+	// Services created before the start are only recorded
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if consumerNotified(t, r, route) {
+		t.Error("expected a Service replayed on startup not to notify consumers")
+	}
+
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}}
+	if err := r.Status().Update(ctx, service); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if !consumerNotified(t, r, route) {
+		t.Error("expected a changed LoadBalancer ingress to notify consumers")
+	}
+	markNotified(t, r, route)
+
+	// Reconciles without changes do not notify
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if consumerNotified(t, r, route) {
+		t.Error("expected an unchanged Service not to notify consumers")
+	}
+
+	if err := r.Delete(ctx, service); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if !consumerNotified(t, r, route) {
+		t.Error("expected a deleted Service to notify consumers")
+	}
+}
@@ -2,15 +2,18 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 
 	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
-
-	utils "github.com/vitistack/gatewayapi-securitypolicy-operator/internal/utils"
+	"golang.org/x/net/http/httpguts"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
-func updateSecurityPolicy(ctx context.Context, r Client, securitypolicy envoyv1.SecurityPolicy, annotations map[string]string) error {
+func updateSecurityPolicy(ctx context.Context, r Client, dnsResolver *DNSResolver, gatewayApiResource gatewayApiResource, securitypolicy envoyv1.SecurityPolicy, annotations map[string]string) error {
 
 	// Declare variables
 	var defaultAction string
@@ -40,56 +43,134 @@ func updateSecurityPolicy(ctx context.Context, r Client, securitypolicy envoyv1.
 		ruleAction = string(envoyv1.AuthorizationActionAllow)
 	}
 
-	// Add PolicyList and PolicyAddresses to slices
-	var sliceAnnotationSecurityPolicyLists []string
-	var sliceAnnotationSecurityPolicyAddresses []string
+	// Check if ruleMode is a valid value
+	ruleMode := RuleModeMerged
+	if value, ok := annotations[AnnotationSecurityPolicyRuleMode]; ok {
+		switch value {
+		case RuleModeMerged, RuleModePerList:
+			ruleMode = value
+		default:
+			return fmt.Errorf("ruleMode not valid. Valid values: %s || %s", RuleModeMerged, RuleModePerList)
+		}
+	}
 
-	if _, ok := annotations[AnnotationSecurityPolicyLists]; ok {
-		sliceAnnotationSecurityPolicyLists = utils.FilterSliceFromString(strings.Split(annotations[AnnotationSecurityPolicyLists], ","))
+	// The shorthand deny rules come first so deny takes precedence over allow, followed
+	// by the rules annotation and the shorthand allow rules
+	shorthandSpecs, err := shorthandRuleSpecs(annotations, ruleAction, ruleMode)
+	if err != nil {
+		return err
+	}
+	var specs []ruleSpec
+	for _, spec := range shorthandSpecs {
+		if spec.template.Action == envoyv1.AuthorizationActionDeny {
+			specs = append(specs, spec)
+		}
+	}
+	if annotation, ok := annotations[AnnotationSecurityPolicyRules]; ok {
+		document, err := parseRulesAnnotation(annotation, gatewayApiResource.Namespace)
+		if err != nil {
+			return fmt.Errorf("invalid %s annotation: %w", AnnotationSecurityPolicyRules, err)
+		}
+		for _, rule := range document.Rules {
+			specs = append(specs, rule.spec())
+		}
+	}
+	for _, spec := range shorthandSpecs {
+		if spec.template.Action != envoyv1.AuthorizationActionDeny {
+			specs = append(specs, spec)
+		}
 	}
 
-	if _, ok := annotations[AnnotationSecurityPolicyAddresses]; ok {
-		sliceAnnotationSecurityPolicyAddresses = utils.FilterSliceFromString(strings.Split(annotations[AnnotationSecurityPolicyAddresses], ","))
+	// Validate the basic auth Secret
+	basicAuth, err := getBasicAuth(ctx, r, gatewayApiResource, annotations)
+	if err != nil {
+		return err
 	}
 
-	// Get addresses
-	cidrs, err := getAddresses(ctx, r, sliceAnnotationSecurityPolicyLists, sliceAnnotationSecurityPolicyAddresses)
+	// Validate the external authorization Service
+	extAuth, err := getExtAuth(ctx, r, gatewayApiResource, annotations)
 	if err != nil {
 		return err
 	}
 
-	// Remove SecurityPolicy Rules if no CIDRs found
-	if len(cidrs) == 0 {
-		defaultActionValue := envoyv1.AuthorizationAction(defaultAction)
-		securitypolicy.Spec.Authorization = &envoyv1.Authorization{
-			DefaultAction: &defaultActionValue,
-			Rules:         []envoyv1.AuthorizationRule{},
+	// Validate the OIDC client Secret
+	oidc, err := getOIDC(ctx, r, gatewayApiResource, annotations)
+	if err != nil {
+		return err
+	}
+
+	// Validate the API key Secrets
+	apiKeyAuth, err := getAPIKeyAuth(ctx, r, gatewayApiResource, annotations)
+	if err != nil {
+		return err
+	}
+
+	// Get CORS
+	cors, err := parseCORSAnnotations(annotations)
+	if err != nil {
+		return err
+	}
+
+	// Rules matching on JWT claims or scopes require the JWT provider
+	jwt, err := jwtProvider(annotations)
+	if err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		if spec.template.Principal.JWT != nil && jwt == nil {
+			return fmt.Errorf("rules matching on JWT claims or scopes require %s", AnnotationSecurityPolicyJWTJWKSURI)
 		}
-		if err := r.Update(ctx, &securitypolicy); err != nil {
-			return fmt.Errorf("failed to update SecurityPolicy: %w", err)
+	}
+
+	// Resolve the dns: entries of all rules at once
+	var addressLists [][]string
+	for _, spec := range specs {
+		for _, entries := range spec.entries {
+			addressLists = append(addressLists, entries.addresses)
 		}
-		return nil
 	}
+	resolvedHosts, err := resolveDNSHosts(ctx, dnsResolver, gatewayApiResource, parseResolvedHostsAnnotation(securitypolicy.Annotations), addressLists...)
+	if err != nil {
+		return err
+	}
+
+	// Get addresses of each rule
+	rules := []envoyv1.AuthorizationRule{}
+	for _, spec := range specs {
+		var sources []addressSource
+		for _, entries := range spec.entries {
+			entrySources, err := getAddresses(ctx, r, gatewayApiResource, entries.namePrefix, entries.lists, entries.addresses, entries.expression, resolvedHosts)
+			if err != nil {
+				return err
+			}
+			sources = append(sources, entrySources...)
+		}
 
-	// Convert string slice to CIDR slice
-	cidrSlice := make([]envoyv1.CIDR, len(cidrs))
-	for i, cidr := range cidrs {
-		cidrSlice[i] = envoyv1.CIDR(cidr)
+		specRules, err := authorizationRules(spec.template, sources, spec.perList)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, specRules...)
 	}
 
-	// Add cidrs to SecurityPolicy rules
-	defaultActionValue := envoyv1.AuthorizationAction(defaultAction)
-	securitypolicy.Spec.Authorization = &envoyv1.Authorization{
-		DefaultAction: &defaultActionValue,
-		Rules: []envoyv1.AuthorizationRule{
-			{
-				Action: envoyv1.AuthorizationAction(ruleAction),
-				Principal: envoyv1.Principal{
-					ClientCIDRs: cidrSlice,
-				},
-			},
-		},
+	// Add rules to SecurityPolicy, no rules are left if no CIDRs are found
+	securitypolicy.Spec.Authorization = nil
+	if hasAuthorizationAnnotations(annotations) {
+		defaultActionValue := envoyv1.AuthorizationAction(defaultAction)
+		securitypolicy.Spec.Authorization = &envoyv1.Authorization{
+			DefaultAction: &defaultActionValue,
+			Rules:         rules,
+		}
 	}
+	if err := setResolvedHostsAnnotation(&securitypolicy, resolvedHosts); err != nil {
+		return err
+	}
+	securitypolicy.Spec.JWT = jwt
+	securitypolicy.Spec.BasicAuth = basicAuth
+	securitypolicy.Spec.CORS = cors
+	securitypolicy.Spec.ExtAuth = extAuth
+	securitypolicy.Spec.OIDC = oidc
+	securitypolicy.Spec.APIKeyAuth = apiKeyAuth
 
 	// Update SecurityPolicy
 	if err := r.Update(ctx, &securitypolicy); err != nil {
@@ -99,3 +180,289 @@ func updateSecurityPolicy(ctx context.Context, r Client, securitypolicy envoyv1.
 	return nil
 
 }
+
+// parseResolvedHostsAnnotation returns the CIDRs of the dns: hosts recorded on a
+// SecurityPolicy, or nil if there are none or the annotation is invalid.
+func parseResolvedHostsAnnotation(annotations map[string]string) map[string][]string {
+	var resolvedHosts map[string][]string
+	if err := json.Unmarshal([]byte(annotations[AnnotationSecurityPolicyResolvedHosts]), &resolvedHosts); err != nil {
+		return nil
+	}
+	return resolvedHosts
+}
+
+// setResolvedHostsAnnotation records the CIDRs of the dns: hosts used by a SecurityPolicy,
+// so a failed lookup after a restart keeps them instead of emptying the rules.
+func setResolvedHostsAnnotation(securityPolicy *envoyv1.SecurityPolicy, resolvedHosts map[string][]string) error {
+	if len(resolvedHosts) == 0 {
+		delete(securityPolicy.Annotations, AnnotationSecurityPolicyResolvedHosts)
+		return nil
+	}
+	value, err := json.Marshal(resolvedHosts)
+	if err != nil {
+		return err
+	}
+	if securityPolicy.Annotations == nil {
+		securityPolicy.Annotations = map[string]string{}
+	}
+	securityPolicy.Annotations[AnnotationSecurityPolicyResolvedHosts] = string(value)
+	return nil
+}
+
+// ruleSpec is an authorization rule before its lists and addresses are resolved.
+// The template holds everything but the client CIDRs.
+type ruleSpec struct {
+	template envoyv1.AuthorizationRule
+	entries  []ruleEntries
+	perList  bool
+}
+
+// ruleEntries are the lists, addresses and expression of a rule. Sources resolved
+// from them are named with namePrefix.
+type ruleEntries struct {
+	namePrefix string
+	lists      []string
+	addresses  []string
+	expression string
+}
+
+// spec converts a rule of the rules annotation into a ruleSpec.
+func (c ruleConfig) spec() ruleSpec {
+	template := envoyv1.AuthorizationRule{Action: envoyv1.AuthorizationActionAllow}
+	if c.Action == "deny" {
+		template.Action = envoyv1.AuthorizationActionDeny
+	}
+	if c.Name != "" {
+		template.Name = &c.Name
+	}
+	if len(c.Methods) > 0 {
+		template.Operation = &envoyv1.Operation{}
+		for _, method := range c.Methods {
+			template.Operation.Methods = append(template.Operation.Methods, gatewayv1.HTTPMethod(method))
+		}
+	}
+	for _, header := range c.Headers {
+		template.Principal.Headers = append(template.Principal.Headers, envoyv1.AuthorizationHeaderMatch{
+			Name:   header.Name,
+			Values: header.Values,
+		})
+	}
+	if c.JWT != nil {
+		template.Principal.JWT = &envoyv1.JWTPrincipal{Provider: JWTProviderName}
+		for _, claim := range c.JWT.Claims {
+			valueType := envoyv1.JWTClaimValueTypeString
+			if claim.ValueType != "" {
+				valueType = envoyv1.JWTClaimValueType(claim.ValueType)
+			}
+			template.Principal.JWT.Claims = append(template.Principal.JWT.Claims, envoyv1.JWTClaim{
+				Name:      claim.Name,
+				ValueType: &valueType,
+				Values:    claim.Values,
+			})
+		}
+		for _, scope := range c.JWT.Scopes {
+			template.Principal.JWT.Scopes = append(template.Principal.JWT.Scopes, envoyv1.JWTScope(scope))
+		}
+	}
+
+	var entries []ruleEntries
+	if c.hasClientCIDRs() {
+		entries = append(entries, ruleEntries{lists: c.Lists, addresses: c.Addresses, expression: c.Expression})
+	}
+
+	return ruleSpec{template: template, entries: entries}
+}
+
+// shorthandRuleSpecs returns the rules described by the lists, addresses and expression
+// annotations together with the allow- and deny- annotations. The lists, addresses and
+// expression annotations are added to the rule with ruleAction, or get a rule of their
+// own after it when their methods, headers or JWT requirements differ.
+// Rules are evaluated in order and the first match wins, so the deny rules
+// come first to take precedence over the allow rules.
+func shorthandRuleSpecs(annotations map[string]string, ruleAction string, ruleMode string) ([]ruleSpec, error) {
+	perList := ruleMode == RuleModePerList
+
+	operation, err := parseMethodsAnnotation(annotations, AnnotationSecurityPolicyMethods)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := parseHeadersAnnotation(annotations, AnnotationSecurityPolicyHeaders)
+	if err != nil {
+		return nil, err
+	}
+	jwtPrincipal, err := parseJWTPrincipalAnnotations(annotations)
+	if err != nil {
+		return nil, err
+	}
+	shorthand := ruleSpec{
+		template: envoyv1.AuthorizationRule{
+			Action:    envoyv1.AuthorizationAction(ruleAction),
+			Operation: operation,
+			Principal: envoyv1.Principal{Headers: headers, JWT: jwtPrincipal},
+		},
+		entries: []ruleEntries{{
+			lists:      annotationEntries(annotations, AnnotationSecurityPolicyLists),
+			addresses:  annotationEntries(annotations, AnnotationSecurityPolicyAddresses),
+			expression: annotations[AnnotationSecurityPolicyExpression],
+		}},
+		perList: perList,
+	}
+
+	denyOperation, err := parseMethodsAnnotation(annotations, AnnotationSecurityPolicyDenyMethods)
+	if err != nil {
+		return nil, err
+	}
+	denyHeaders, err := parseHeadersAnnotation(annotations, AnnotationSecurityPolicyDenyHeaders)
+	if err != nil {
+		return nil, err
+	}
+	deny := ruleSpec{
+		template: envoyv1.AuthorizationRule{
+			Action:    envoyv1.AuthorizationActionDeny,
+			Operation: denyOperation,
+			Principal: envoyv1.Principal{Headers: denyHeaders},
+		},
+		entries: []ruleEntries{{
+			namePrefix: "deny-",
+			lists:      annotationEntries(annotations, AnnotationSecurityPolicyDenyLists),
+			addresses:  annotationEntries(annotations, AnnotationSecurityPolicyDenyAddresses),
+		}},
+		perList: perList,
+	}
+
+	allowOperation, err := parseMethodsAnnotation(annotations, AnnotationSecurityPolicyAllowMethods)
+	if err != nil {
+		return nil, err
+	}
+	allowHeaders, err := parseHeadersAnnotation(annotations, AnnotationSecurityPolicyAllowHeaders)
+	if err != nil {
+		return nil, err
+	}
+	allow := ruleSpec{
+		template: envoyv1.AuthorizationRule{
+			Action:    envoyv1.AuthorizationActionAllow,
+			Operation: allowOperation,
+			Principal: envoyv1.Principal{Headers: allowHeaders},
+		},
+		entries: []ruleEntries{{
+			namePrefix: "allow-",
+			lists:      annotationEntries(annotations, AnnotationSecurityPolicyAllowLists),
+			addresses:  annotationEntries(annotations, AnnotationSecurityPolicyAllowAddresses),
+		}},
+		perList: perList,
+	}
+
+	specs := []ruleSpec{deny, allow}
+	index := 1
+	if ruleAction == string(envoyv1.AuthorizationActionDeny) {
+		index = 0
+	}
+	if reflect.DeepEqual(specs[index].template, shorthand.template) {
+		specs[index].entries = append(specs[index].entries, shorthand.entries...)
+	} else {
+		specs = slices.Insert(specs, index+1, shorthand)
+	}
+
+	return specs, nil
+}
+
+// parseMethodsAnnotation returns the operation matching the comma separated HTTP
+// methods of the annotation, or nil if the annotation is not set.
+func parseMethodsAnnotation(annotations map[string]string, annotation string) (*envoyv1.Operation, error) {
+	methods := annotationEntries(annotations, annotation)
+	if len(methods) == 0 {
+		return nil, nil
+	}
+
+	operation := &envoyv1.Operation{}
+	for _, method := range methods {
+		if !slices.Contains(supportedRuleMethods, method) {
+			return nil, fmt.Errorf("%s not valid: %q. Valid values: %s", annotation, method, strings.Join(supportedRuleMethods, " || "))
+		}
+		if !slices.Contains(operation.Methods, gatewayv1.HTTPMethod(method)) {
+			operation.Methods = append(operation.Methods, gatewayv1.HTTPMethod(method))
+		}
+	}
+
+	return operation, nil
+}
+
+// parseHeadersAnnotation returns the header matches of the comma separated
+// "name=value" entries of the annotation. Entries with the same header name are
+// combined, any of their values matches. All headers must match.
+func parseHeadersAnnotation(annotations map[string]string, annotation string) ([]envoyv1.AuthorizationHeaderMatch, error) {
+	var headers []envoyv1.AuthorizationHeaderMatch
+	for _, entry := range annotationEntries(annotations, annotation) {
+		name, value, found := strings.Cut(entry, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !found || value == "" {
+			return nil, fmt.Errorf("%s not valid: %q. Expected name=value", annotation, entry)
+		}
+		if !httpguts.ValidHeaderFieldName(name) {
+			return nil, fmt.Errorf("%s not valid: %q is not a valid header name", annotation, name)
+		}
+
+		index := slices.IndexFunc(headers, func(header envoyv1.AuthorizationHeaderMatch) bool {
+			return strings.EqualFold(header.Name, name)
+		})
+		if index < 0 {
+			headers = append(headers, envoyv1.AuthorizationHeaderMatch{Name: name})
+			index = len(headers) - 1
+		}
+		if !slices.Contains(headers[index].Values, value) {
+			headers[index].Values = append(headers[index].Values, value)
+		}
+	}
+
+	return headers, nil
+}
+
+// authorizationRules returns the rules for the given sources based on template.
+// Without per-list the CIDRs of all sources are combined into a single rule. With
+// per-list every source gets its own rule named after the source, in the order of
+// the sources. Sources without CIDRs produce no rule, so a rule never matches more
+// clients than its lists contain. A template without sources matches on its other
+// principals only, and produces no rule if it has none.
+func authorizationRules(template envoyv1.AuthorizationRule, sources []addressSource, perList bool) ([]envoyv1.AuthorizationRule, error) {
+
+	if len(sources) == 0 {
+		if len(template.Principal.Headers) == 0 && template.Principal.JWT == nil {
+			return nil, nil
+		}
+		return []envoyv1.AuthorizationRule{*template.DeepCopy()}, nil
+	}
+
+	if !perList {
+		var cidrs []string
+		for _, source := range sources {
+			cidrs = append(cidrs, source.CIDRs...)
+		}
+		merged, err := newAddressSource("", cidrs)
+		if err != nil {
+			return nil, err
+		}
+		sources = []addressSource{merged}
+	}
+
+	var rules []envoyv1.AuthorizationRule
+	for _, source := range sources {
+		if len(source.CIDRs) == 0 {
+			continue
+		}
+
+		// Convert string slice to CIDR slice
+		cidrSlice := make([]envoyv1.CIDR, len(source.CIDRs))
+		for i, cidr := range source.CIDRs {
+			cidrSlice[i] = envoyv1.CIDR(cidr)
+		}
+
+		rule := *template.DeepCopy()
+		rule.Principal.ClientCIDRs = cidrSlice
+		if source.Name != "" {
+			rule.Name = &source.Name
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
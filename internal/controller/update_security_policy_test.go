@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	envoyv1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAuthorizationRules(t *testing.T) {
+	sources := []addressSource{
+		{Name: "list-expose-thula", CIDRs: []string{"10.0.0.0/25", "10.0.0.128/25"}},
+		{Name: "list-empty"},
+		{Name: "addresses", CIDRs: []string{"192.0.2.10/32"}},
+	}
+
+	// Merged mode combines all sources into one unnamed rule
+	rules, err := authorizationRules(envoyv1.AuthorizationRule{Action: envoyv1.AuthorizationActionAllow}, sources, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].Name != nil {
+		t.Fatalf("got %+v, want a single unnamed rule", rules)
+	}
+	if got := rules[0].Principal.ClientCIDRs; len(got) != 2 || got[0] != "10.0.0.0/24" || got[1] != "192.0.2.10/32" {
+		t.Errorf("ClientCIDRs = %v, want [10.0.0.0/24 192.0.2.10/32]", got)
+	}
+
+	// Per-list mode keeps one named rule per source, skipping empty sources
+	rules, err = authorizationRules(envoyv1.AuthorizationRule{Action: envoyv1.AuthorizationActionDeny}, sources, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, rule := range rules {
+		if rule.Action != envoyv1.AuthorizationActionDeny {
+			t.Errorf("rule %s has action %s, want Deny", *rule.Name, rule.Action)
+		}
+		names = append(names, *rule.Name)
+	}
+	if len(names) != 2 || names[0] != "list-expose-thula" || names[1] != "addresses" {
+		t.Errorf("rule names = %v, want [list-expose-thula addresses]", names)
+	}
+}
+
+func TestAuthorizationRulesWithoutSources(t *testing.T) {
+	headers := []envoyv1.AuthorizationHeaderMatch{{Name: "x-internal-caller", Values: []string{"billing"}}}
+
+	// A rule matching on headers only is kept as is
+	rules, err := authorizationRules(envoyv1.AuthorizationRule{Action: envoyv1.AuthorizationActionAllow, Principal: envoyv1.Principal{Headers: headers}}, nil, false)
+	if err != nil || len(rules) != 1 {
+		t.Fatalf("got %+v, %v, want a single rule", rules, err)
+	}
+
+	// A rule whose lists are empty must not fall back to matching on headers only
+	rules, err = authorizationRules(envoyv1.AuthorizationRule{Action: envoyv1.AuthorizationActionAllow, Principal: envoyv1.Principal{Headers: headers}}, []addressSource{{Name: "list-empty"}}, false)
+	if err != nil || len(rules) != 0 {
+		t.Errorf("got %+v, %v, want no rules", rules, err)
+	}
+}
+
+func TestShorthandRuleSpecs(t *testing.T) {
+	// Lists without methods are merged into the allow rule
+	specs, err := shorthandRuleSpecs(map[string]string{
+		AnnotationSecurityPolicyLists:      "partners",
+		AnnotationSecurityPolicyAllowLists: "office",
+	}, string(envoyv1.AuthorizationActionAllow), RuleModeMerged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 2 || len(specs[1].entries) != 2 {
+		t.Fatalf("got %+v, want the lists merged into the allow rule", specs)
+	}
+
+	// Lists with other methods get a rule of their own after the allow rule
+	specs, err = shorthandRuleSpecs(map[string]string{
+		AnnotationSecurityPolicyLists:        "partners",
+		AnnotationSecurityPolicyMethods:      "GET,HEAD",
+		AnnotationSecurityPolicyAllowLists:   "office",
+		AnnotationSecurityPolicyAllowMethods: "POST, PUT, DELETE",
+	}, string(envoyv1.AuthorizationActionAllow), RuleModeMerged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 3 {
+		t.Fatalf("got %d rules, want 3", len(specs))
+	}
+	if methods := specs[1].template.Operation.Methods; len(methods) != 3 || methods[0] != "POST" {
+		t.Errorf("allow rule methods = %v, want [POST PUT DELETE]", methods)
+	}
+	if methods := specs[2].template.Operation.Methods; len(methods) != 2 || methods[0] != "GET" {
+		t.Errorf("lists rule methods = %v, want [GET HEAD]", methods)
+	}
+
+	if _, err := shorthandRuleSpecs(map[string]string{AnnotationSecurityPolicyMethods: "get"}, string(envoyv1.AuthorizationActionAllow), RuleModeMerged); err == nil {
+		t.Error("expected error for invalid method")
+	}
+}
+
+func TestParseHeadersAnnotation(t *testing.T) {
+	headers, err := parseHeadersAnnotation(map[string]string{
+		AnnotationSecurityPolicyHeaders: "x-internal-caller=billing, x-env=prod, X-Internal-Caller=payments",
+	}, AnnotationSecurityPolicyHeaders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(headers) != 2 || headers[0].Name != "x-internal-caller" || len(headers[0].Values) != 2 || headers[1].Name != "x-env" {
+		t.Errorf("got %+v, want x-internal-caller=[billing payments] and x-env=[prod]", headers)
+	}
+
+	for _, annotation := range []string{"x-internal-caller", "x-internal-caller=", "x internal=billing"} {
+		if _, err := parseHeadersAnnotation(map[string]string{AnnotationSecurityPolicyHeaders: annotation}, AnnotationSecurityPolicyHeaders); err == nil {
+			t.Errorf("expected error for %q", annotation)
+		}
+	}
+}
+
+// updateTestSecurityPolicy runs updateSecurityPolicy on a new SecurityPolicy of route
+// and returns its authorization rules.
+func updateTestSecurityPolicy(t *testing.T, annotations map[string]string) []envoyv1.AuthorizationRule {
+	t.Helper()
+	ctx := context.Background()
+	route := gatewayApiResource{Kind: "HTTPRoute", Namespace: "default", Name: "api"}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := envoyv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	securityPolicy := envoyv1.SecurityPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: route.securityPolicyName()}}
+	r := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&securityPolicy).Build()
+	if err := r.Get(ctx, client.ObjectKeyFromObject(&securityPolicy), &securityPolicy); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := updateSecurityPolicy(ctx, r, nil, route, securityPolicy, annotations); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(&securityPolicy), &securityPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if securityPolicy.Spec.Authorization == nil {
+		t.Fatal("expected authorization to be set")
+	}
+	return securityPolicy.Spec.Authorization.Rules
+}
+
+func TestUpdateSecurityPolicyRuleOrder(t *testing.T) {
+	// Deny addresses take precedence over an allow rule of the rules annotation
+	rules := updateTestSecurityPolicy(t, map[string]string{
+		AnnotationSecurityPolicyDenyAddresses: "192.0.2.10/32",
+		AnnotationSecurityPolicyAddresses:     "198.51.100.0/24",
+		AnnotationSecurityPolicyRules: `
+version: v1
+rules:
+- name: office
+  action: allow
+  addresses: [192.0.2.0/24]
+`,
+	})
+
+	if len(rules) != 3 {
+		t.Fatalf("got %d rules, want 3: %+v", len(rules), rules)
+	}
+	want := []struct {
+		action envoyv1.AuthorizationAction
+		cidr   envoyv1.CIDR
+	}{
+		{envoyv1.AuthorizationActionDeny, "192.0.2.10/32"},
+		{envoyv1.AuthorizationActionAllow, "192.0.2.0/24"},
+		{envoyv1.AuthorizationActionAllow, "198.51.100.0/24"},
+	}
+	for i, rule := range rules {
+		if rule.Action != want[i].action || len(rule.Principal.ClientCIDRs) != 1 || rule.Principal.ClientCIDRs[0] != want[i].cidr {
+			t.Errorf("rule %d = %s %v, want %s %s", i, rule.Action, rule.Principal.ClientCIDRs, want[i].action, want[i].cidr)
+		}
+	}
+	if rules[1].Name == nil || *rules[1].Name != "office" {
+		t.Errorf("expected the rules annotation rule second, got %+v", rules[1])
+	}
+}
+
+func TestUpdateSecurityPolicyShorthandRules(t *testing.T) {
+	// With the default deny action, addresses are merged into the allow rule after the deny rule
+	rules := updateTestSecurityPolicy(t, map[string]string{
+		AnnotationSecurityPolicyAddresses:      "198.51.100.0/24",
+		AnnotationSecurityPolicyAllowAddresses: "203.0.113.0/24",
+		AnnotationSecurityPolicyDenyAddresses:  "203.0.113.10/32",
+	})
+	if len(rules) != 2 || rules[0].Action != envoyv1.AuthorizationActionDeny || rules[1].Action != envoyv1.AuthorizationActionAllow {
+		t.Fatalf("got %+v, want a deny rule followed by an allow rule", rules)
+	}
+	if cidrs := rules[0].Principal.ClientCIDRs; len(cidrs) != 1 || cidrs[0] != "203.0.113.10/32" {
+		t.Errorf("deny rule ClientCIDRs = %v, want [203.0.113.10/32]", cidrs)
+	}
+	if cidrs := rules[1].Principal.ClientCIDRs; len(cidrs) != 2 || cidrs[0] != "198.51.100.0/24" || cidrs[1] != "203.0.113.0/24" {
+		t.Errorf("allow rule ClientCIDRs = %v, want [198.51.100.0/24 203.0.113.0/24]", cidrs)
+	}
+
+	// With the allow default action, addresses are merged into the deny rule
+	rules = updateTestSecurityPolicy(t, map[string]string{
+		AnnotationSecurityPolicyDefaultAction:  "allow",
+		AnnotationSecurityPolicyAddresses:      "198.51.100.0/24",
+		AnnotationSecurityPolicyAllowAddresses: "198.51.100.10/32",
+		AnnotationSecurityPolicyDenyAddresses:  "203.0.113.0/24",
+	})
+	if len(rules) != 2 || rules[0].Action != envoyv1.AuthorizationActionDeny || rules[1].Action != envoyv1.AuthorizationActionAllow {
+		t.Fatalf("got %+v, want a deny rule followed by an allow rule", rules)
+	}
+	if cidrs := rules[0].Principal.ClientCIDRs; len(cidrs) != 2 || cidrs[0] != "198.51.100.0/24" || cidrs[1] != "203.0.113.0/24" {
+		t.Errorf("deny rule ClientCIDRs = %v, want [198.51.100.0/24 203.0.113.0/24]", cidrs)
+	}
+	if cidrs := rules[1].Principal.ClientCIDRs; len(cidrs) != 1 || cidrs[0] != "198.51.100.10/32" {
+		t.Errorf("allow rule ClientCIDRs = %v, want [198.51.100.10/32]", cidrs)
+	}
+}
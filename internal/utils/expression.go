@@ -0,0 +1,249 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operators of set expressions. The keywords "or", "and" and "minus" may be
+// used instead of the symbols. Intersection binds tighter than union and
+// difference, which are evaluated from left to right.
+const (
+	ExpressionUnion        = '|'
+	ExpressionIntersection = '&'
+	ExpressionDifference   = '-'
+)
+
+// Expression is a node of a parsed set expression.
+type Expression interface {
+	// Position returns the 1-based position of the node in the expression.
+	Position() int
+}
+
+// ExpressionOperand is a list reference or literal CIDR in a set expression.
+type ExpressionOperand struct {
+	Value string
+	Pos   int
+}
+
+// ExpressionBinary combines two set expressions with an operator.
+type ExpressionBinary struct {
+	Operator byte
+	Left     Expression
+	Right    Expression
+	Pos      int
+}
+
+func (o ExpressionOperand) Position() int { return o.Pos }
+func (b ExpressionBinary) Position() int  { return b.Pos }
+
+// ExpressionError is an error at a 1-based position of a set expression.
+type ExpressionError struct {
+	Pos     int
+	Message string
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Message)
+}
+
+// expressionKeywords maps the operator keywords to their symbols.
+var expressionKeywords = map[string]byte{
+	"or":    ExpressionUnion,
+	"and":   ExpressionIntersection,
+	"minus": ExpressionDifference,
+}
+
+type expressionTokenKind int
+
+const (
+	expressionTokenEnd expressionTokenKind = iota
+	expressionTokenOperand
+	expressionTokenOperator
+	expressionTokenOpen
+	expressionTokenClose
+)
+
+type expressionToken struct {
+	kind     expressionTokenKind
+	value    string
+	operator byte
+	pos      int
+}
+
+// tokenizeExpression splits a set expression into tokens. Operands consist of
+// letters, digits and ".:/@_-", but may not start with "-", so names such as
+// "office-vpn" are operands while a separate "-" is the difference operator.
+func tokenizeExpression(input string) ([]expressionToken, error) {
+	var tokens []expressionToken
+
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, expressionToken{kind: expressionTokenOpen, value: "(", pos: i + 1})
+			i++
+		case c == ')':
+			tokens = append(tokens, expressionToken{kind: expressionTokenClose, value: ")", pos: i + 1})
+			i++
+		case c == ExpressionUnion || c == ExpressionIntersection || c == ExpressionDifference:
+			tokens = append(tokens, expressionToken{kind: expressionTokenOperator, value: string(c), operator: c, pos: i + 1})
+			i++
+		case isExpressionOperandChar(c):
+			start := i
+			for i < len(input) && isExpressionOperandChar(input[i]) {
+				i++
+			}
+			value := input[start:i]
+			if operator, ok := expressionKeywords[strings.ToLower(value)]; ok {
+				tokens = append(tokens, expressionToken{kind: expressionTokenOperator, value: value, operator: operator, pos: start + 1})
+			} else {
+				tokens = append(tokens, expressionToken{kind: expressionTokenOperand, value: value, pos: start + 1})
+			}
+		default:
+			return nil, &ExpressionError{Pos: i + 1, Message: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+
+	return append(tokens, expressionToken{kind: expressionTokenEnd, pos: len(input) + 1}), nil
+}
+
+func isExpressionOperandChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte(".:/@_-", c) >= 0
+}
+
+// expressionParser is a recursive descent parser for set expressions:
+//
+//	expression = term { ( "|" | "-" ) term }
+//	term       = primary { "&" primary }
+//	primary    = operand | "(" expression ")"
+type expressionParser struct {
+	tokens []expressionToken
+	next   int
+}
+
+// ParseExpression parses a set expression over list references and literal CIDRs,
+// e.g. "(office | vpn) - contractors". Errors are of type *ExpressionError.
+func ParseExpression(input string) (Expression, error) {
+	tokens, err := tokenizeExpression(input)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &expressionParser{tokens: tokens}
+	if parser.peek().kind == expressionTokenEnd {
+		return nil, &ExpressionError{Pos: 1, Message: "empty expression"}
+	}
+
+	expression, err := parser.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != expressionTokenEnd {
+		return nil, &ExpressionError{Pos: token.pos, Message: fmt.Sprintf("unexpected %q, expected an operator", token.value)}
+	}
+
+	return expression, nil
+}
+
+func (p *expressionParser) peek() expressionToken {
+	return p.tokens[p.next]
+}
+
+func (p *expressionParser) parseExpression() (Expression, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for token := p.peek(); token.kind == expressionTokenOperator && token.operator != ExpressionIntersection; token = p.peek() {
+		p.next++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = ExpressionBinary{Operator: token.operator, Left: left, Right: right, Pos: token.pos}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseTerm() (Expression, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for token := p.peek(); token.kind == expressionTokenOperator && token.operator == ExpressionIntersection; token = p.peek() {
+		p.next++
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = ExpressionBinary{Operator: token.operator, Left: left, Right: right, Pos: token.pos}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parsePrimary() (Expression, error) {
+	token := p.peek()
+	switch token.kind {
+	case expressionTokenOperand:
+		p.next++
+		return ExpressionOperand{Value: token.value, Pos: token.pos}, nil
+	case expressionTokenOpen:
+		p.next++
+		expression, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing.kind != expressionTokenClose {
+			return nil, &ExpressionError{Pos: closing.pos, Message: fmt.Sprintf("missing ')' for '(' at position %d", token.pos)}
+		}
+		p.next++
+		return expression, nil
+	case expressionTokenEnd:
+		return nil, &ExpressionError{Pos: token.pos, Message: "unexpected end of expression, expected a list or CIDR"}
+	default:
+		return nil, &ExpressionError{Pos: token.pos, Message: fmt.Sprintf("unexpected %q, expected a list or CIDR", token.value)}
+	}
+}
+
+// ExpressionOperands returns the operands of an expression from left to right.
+func ExpressionOperands(expression Expression) []ExpressionOperand {
+	switch node := expression.(type) {
+	case ExpressionOperand:
+		return []ExpressionOperand{node}
+	case ExpressionBinary:
+		return append(ExpressionOperands(node.Left), ExpressionOperands(node.Right)...)
+	default:
+		return nil
+	}
+}
+
+// EvaluateExpression evaluates an expression, resolving each operand to a prefix set.
+func EvaluateExpression(expression Expression, resolve func(operand ExpressionOperand) (*PrefixSet, error)) (*PrefixSet, error) {
+	switch node := expression.(type) {
+	case ExpressionOperand:
+		return resolve(node)
+	case ExpressionBinary:
+		left, err := EvaluateExpression(node.Left, resolve)
+		if err != nil {
+			return nil, err
+		}
+		right, err := EvaluateExpression(node.Right, resolve)
+		if err != nil {
+			return nil, err
+		}
+		switch node.Operator {
+		case ExpressionUnion:
+			return left.Union(right), nil
+		case ExpressionIntersection:
+			return left.Intersect(right), nil
+		default:
+			return left.Subtract(right), nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported expression node %T", expression)
+	}
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantPos int
+	}{
+		{input: "office", want: "office"},
+		{input: "office | vpn - contractors", want: "((office | vpn) - contractors)"},
+		{input: "office or vpn MINUS contractors", want: "((office | vpn) - contractors)"},
+		{input: "office | vpn & contractors", want: "(office | (vpn & contractors))"},
+		{input: "(office | vpn) & local:tenant-list", want: "((office | vpn) & local:tenant-list)"},
+		{input: "addresslist:expose-thula - 10.0.0.0/8", want: "(addresslist:expose-thula - 10.0.0.0/8)"},
+		{input: "", wantPos: 1},
+		{input: "office |", wantPos: 9},
+		{input: "(office | vpn", wantPos: 14},
+		{input: "office vpn", wantPos: 8},
+		{input: "office & ) vpn", wantPos: 10},
+		{input: "office # vpn", wantPos: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expression, err := ParseExpression(tt.input)
+			if tt.wantPos > 0 {
+				var expressionErr *ExpressionError
+				if !errors.As(err, &expressionErr) || expressionErr.Pos != tt.wantPos {
+					t.Fatalf("ParseExpression() error = %v, want error at position %d", err, tt.wantPos)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseExpression() error = %v", err)
+			}
+			if got := formatExpression(expression); got != tt.want {
+				t.Errorf("ParseExpression() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateExpression(t *testing.T) {
+	lists := map[string][]string{
+		"office":      {"10.1.0.0/16"},
+		"vpn":         {"10.2.0.0/16", "2001:db8::/48"},
+		"contractors": {"10.1.128.0/17", "10.2.0.0/24"},
+	}
+
+	expression, err := ParseExpression("(office | vpn | 192.168.0.0/24) - contractors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := EvaluateExpression(expression, func(operand ExpressionOperand) (*PrefixSet, error) {
+		if cidrs, ok := lists[operand.Value]; ok {
+			return NewPrefixSet(cidrs...)
+		}
+		return NewPrefixSet(operand.Value)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"10.1.0.0/17",
+		"10.2.1.0/24", "10.2.2.0/23", "10.2.4.0/22", "10.2.8.0/21", "10.2.16.0/20", "10.2.32.0/19", "10.2.64.0/18", "10.2.128.0/17",
+		"192.168.0.0/24",
+		"2001:db8::/48",
+	}
+	if got := set.Strings(); !slices.Equal(got, want) {
+		t.Errorf("EvaluateExpression() = %v, want %v", got, want)
+	}
+
+	office, _ := NewPrefixSet(lists["office"]...)
+	contractors, _ := NewPrefixSet(lists["contractors"]...)
+	if got := office.Intersect(contractors).Strings(); !slices.Equal(got, []string{"10.1.128.0/17"}) {
+		t.Errorf("Intersect() = %v, want [10.1.128.0/17]", got)
+	}
+}
+
+func formatExpression(expression Expression) string {
+	switch node := expression.(type) {
+	case ExpressionOperand:
+		return node.Value
+	case ExpressionBinary:
+		return fmt.Sprintf("(%s %c %s)", formatExpression(node.Left), node.Operator, formatExpression(node.Right))
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseCIDRList parses a newline- or comma-separated list of CIDRs.
+// Everything after '#' on a line is a comment. Empty entries are ignored.
+func ParseCIDRList(text string) ([]string, error) {
+
+	var cidrs []string
+
+	for i, line := range strings.Split(text, "\n") {
+		// Strip comments
+		if before, _, found := strings.Cut(line, "#"); found {
+			line = before
+		}
+
+		for _, entry := range FilterSliceFromString(strings.Split(line, ",")) {
+			if entry == "" {
+				continue
+			}
+			if !CheckValidCIDR(entry) {
+				return nil, fmt.Errorf("invalid CIDR %q on line %d", entry, i+1)
+			}
+			cidrs = append(cidrs, entry)
+		}
+	}
+
+	return cidrs, nil
+}
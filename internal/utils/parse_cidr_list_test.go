@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseCIDRList(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "empty",
+			text: "",
+			want: nil,
+		},
+		{
+			name: "newline separated",
+			text: "10.0.0.0/8\n192.0.2.0/24\n2001:db8::/32",
+			want: []string{"10.0.0.0/8", "192.0.2.0/24", "2001:db8::/32"},
+		},
+		{
+			name: "comma separated with spaces",
+			text: "10.0.0.0/8, 192.0.2.0/24 ,2001:db8::/32",
+			want: []string{"10.0.0.0/8", "192.0.2.0/24", "2001:db8::/32"},
+		},
+		{
+			name: "comments and blank lines",
+			text: "# partners\n\n10.0.0.0/8 # office\n  \n# 192.0.2.0/24\n198.51.100.0/24,\n",
+			want: []string{"10.0.0.0/8", "198.51.100.0/24"},
+		},
+		{
+			name:    "invalid cidr",
+			text:    "10.0.0.0/8\n# comment\n10.0.0.1, not-a-cidr",
+			wantErr: `invalid CIDR "10.0.0.1" on line 3`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCIDRList(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseCIDRList() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCIDRList() unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseCIDRList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
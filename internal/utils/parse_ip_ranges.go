@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Formats of published IP range documents understood by ParseIPRanges.
+const (
+	IPRangesFormatPlain  = "Plain"
+	IPRangesFormatAWS    = "AWS"
+	IPRangesFormatGCP    = "GCP"
+	IPRangesFormatAzure  = "Azure"
+	IPRangesFormatGitHub = "GitHub"
+)
+
+// ParseIPRanges parses an IP range document in the given format and returns the
+// CIDRs whose service and region match the filters. Filters are matched
+// case-insensitively and an empty filter matches everything. Filters that match
+// no IP ranges are an error.
+//
+// Supported formats are:
+//   - Plain: newline- or comma-separated CIDRs, see ParseCIDRList
+//   - AWS: https://ip-ranges.amazonaws.com/ip-ranges.json
+//   - GCP: https://www.gstatic.com/ipranges/cloud.json, the scope is the region
+//   - Azure: the Service Tags JSON file, the service is the service tag name, e.g. "AzureFrontDoor.Backend"
+//   - GitHub: https://api.github.com/meta, the service is the key, e.g. "actions". Regions are not supported.
+func ParseIPRanges(format string, body []byte, services []string, regions []string) ([]string, error) {
+
+	var cidrs []string
+	var err error
+	switch format {
+	case "", IPRangesFormatPlain:
+		if len(services) > 0 || len(regions) > 0 {
+			return nil, fmt.Errorf("format %s does not support service or region filters", IPRangesFormatPlain)
+		}
+		return ParseCIDRList(string(body))
+	case IPRangesFormatAWS:
+		cidrs, err = parseAWSIPRanges(body, services, regions)
+	case IPRangesFormatGCP:
+		cidrs, err = parseGCPIPRanges(body, services, regions)
+	case IPRangesFormatAzure:
+		cidrs, err = parseAzureIPRanges(body, services, regions)
+	case IPRangesFormatGitHub:
+		cidrs, err = parseGitHubIPRanges(body, services, regions)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A misspelled filter must not silently empty the list
+	if len(cidrs) == 0 && (len(services) > 0 || len(regions) > 0) {
+		return nil, fmt.Errorf("no %s IP ranges match services %q and regions %q", format, services, regions)
+	}
+
+	return cidrs, nil
+}
+
+// matchesFilter reports whether value matches any of the filter entries.
+func matchesFilter(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(filter, func(f string) bool {
+		return strings.EqualFold(f, value)
+	})
+}
+
+// appendCIDR validates and appends a CIDR published by the given provider.
+func appendCIDR(cidrs []string, provider string, cidr string) ([]string, error) {
+	if !CheckValidCIDR(cidr) {
+		return nil, fmt.Errorf("invalid CIDR %q in %s IP ranges", cidr, provider)
+	}
+	return append(cidrs, strings.TrimSpace(cidr)), nil
+}
+
+func parseAWSIPRanges(body []byte, services []string, regions []string) ([]string, error) {
+	var document struct {
+		Prefixes []struct {
+			IPPrefix string `json:"ip_prefix"`
+			Region   string `json:"region"`
+			Service  string `json:"service"`
+		} `json:"prefixes"`
+		IPv6Prefixes []struct {
+			IPv6Prefix string `json:"ipv6_prefix"`
+			Region     string `json:"region"`
+			Service    string `json:"service"`
+		} `json:"ipv6_prefixes"`
+	}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("invalid AWS IP ranges: %w", err)
+	}
+
+	var cidrs []string
+	var err error
+	for _, prefix := range document.Prefixes {
+		if matchesFilter(services, prefix.Service) && matchesFilter(regions, prefix.Region) {
+			if cidrs, err = appendCIDR(cidrs, IPRangesFormatAWS, prefix.IPPrefix); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, prefix := range document.IPv6Prefixes {
+		if matchesFilter(services, prefix.Service) && matchesFilter(regions, prefix.Region) {
+			if cidrs, err = appendCIDR(cidrs, IPRangesFormatAWS, prefix.IPv6Prefix); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return cidrs, nil
+}
+
+func parseGCPIPRanges(body []byte, services []string, regions []string) ([]string, error) {
+	var document struct {
+		Prefixes []struct {
+			IPv4Prefix string `json:"ipv4Prefix"`
+			IPv6Prefix string `json:"ipv6Prefix"`
+			Service    string `json:"service"`
+			Scope      string `json:"scope"`
+		} `json:"prefixes"`
+	}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("invalid GCP IP ranges: %w", err)
+	}
+
+	var cidrs []string
+	var err error
+	for _, prefix := range document.Prefixes {
+		if !matchesFilter(services, prefix.Service) || !matchesFilter(regions, prefix.Scope) {
+			continue
+		}
+		for _, cidr := range []string{prefix.IPv4Prefix, prefix.IPv6Prefix} {
+			if cidr == "" {
+				continue
+			}
+			if cidrs, err = appendCIDR(cidrs, IPRangesFormatGCP, cidr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return cidrs, nil
+}
+
+func parseAzureIPRanges(body []byte, services []string, regions []string) ([]string, error) {
+	var document struct {
+		Values []struct {
+			Name       string `json:"name"`
+			Properties struct {
+				Region          string   `json:"region"`
+				AddressPrefixes []string `json:"addressPrefixes"`
+			} `json:"properties"`
+		} `json:"values"`
+	}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("invalid Azure service tags: %w", err)
+	}
+
+	var cidrs []string
+	var err error
+	for _, value := range document.Values {
+		if !matchesFilter(services, value.Name) || !matchesFilter(regions, value.Properties.Region) {
+			continue
+		}
+		for _, cidr := range value.Properties.AddressPrefixes {
+			if cidrs, err = appendCIDR(cidrs, IPRangesFormatAzure, cidr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return cidrs, nil
+}
+
+func parseGitHubIPRanges(body []byte, services []string, regions []string) ([]string, error) {
+	if len(regions) > 0 {
+		return nil, fmt.Errorf("format %s does not support region filters", IPRangesFormatGitHub)
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("format %s requires at least one service, e.g. \"actions\"", IPRangesFormatGitHub)
+	}
+
+	// The document mixes CIDR lists with other metadata, so only the selected keys are decoded
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("invalid GitHub meta: %w", err)
+	}
+
+	var cidrs []string
+	for key, raw := range document {
+		if !matchesFilter(services, key) {
+			continue
+		}
+		var prefixes []string
+		if err := json.Unmarshal(raw, &prefixes); err != nil {
+			return nil, fmt.Errorf("invalid GitHub meta key %q: %w", key, err)
+		}
+		for _, cidr := range prefixes {
+			var err error
+			if cidrs, err = appendCIDR(cidrs, IPRangesFormatGitHub, cidr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return cidrs, nil
+}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseIPRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		body     string
+		services []string
+		regions  []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:   "plain",
+			format: IPRangesFormatPlain,
+			body:   "10.0.0.0/8 # office\n192.168.0.0/16",
+			want:   []string{"10.0.0.0/8", "192.168.0.0/16"},
+		},
+		{
+			name:     "plain with filter",
+			format:   IPRangesFormatPlain,
+			body:     "10.0.0.0/8",
+			services: []string{"office"},
+			wantErr:  true,
+		},
+		{
+			name:   "aws by region",
+			format: IPRangesFormatAWS,
+			body: `{"prefixes":[
+				{"ip_prefix":"3.5.140.0/22","region":"ap-northeast-2","service":"AMAZON"},
+				{"ip_prefix":"3.248.0.0/13","region":"eu-west-1","service":"EC2"}
+			],"ipv6_prefixes":[
+				{"ipv6_prefix":"2a05:d018::/36","region":"eu-west-1","service":"EC2"}
+			]}`,
+			regions: []string{"eu-west-1"},
+			want:    []string{"3.248.0.0/13", "2a05:d018::/36"},
+		},
+		{
+			name:   "gcp by scope",
+			format: IPRangesFormatGCP,
+			body: `{"prefixes":[
+				{"ipv4Prefix":"34.1.208.0/20","service":"Google Cloud","scope":"africa-south1"},
+				{"ipv6Prefix":"2600:1900:8000::/44","service":"Google Cloud","scope":"europe-north1"}
+			]}`,
+			services: []string{"google cloud"},
+			regions:  []string{"europe-north1"},
+			want:     []string{"2600:1900:8000::/44"},
+		},
+		{
+			name:   "azure by service tag",
+			format: IPRangesFormatAzure,
+			body: `{"values":[
+				{"name":"AzureFrontDoor.Backend","properties":{"region":"","addressPrefixes":["147.243.0.0/16","2a01:111:2050::/44"]}},
+				{"name":"AzureCloud.westeurope","properties":{"region":"westeurope","addressPrefixes":["13.69.0.0/17"]}}
+			]}`,
+			services: []string{"AzureFrontDoor.Backend"},
+			want:     []string{"147.243.0.0/16", "2a01:111:2050::/44"},
+		},
+		{
+			name:     "github by key",
+			format:   IPRangesFormatGitHub,
+			body:     `{"verifiable_password_authentication":false,"hooks":["192.30.252.0/22"],"actions":["4.148.0.0/16"],"ssh_keys":["ssh-ed25519 AAAA"]}`,
+			services: []string{"actions"},
+			want:     []string{"4.148.0.0/16"},
+		},
+		{
+			name:    "github without service",
+			format:  IPRangesFormatGitHub,
+			body:    `{"actions":["4.148.0.0/16"]}`,
+			wantErr: true,
+		},
+		{
+			name:   "aws filter matches nothing",
+			format: IPRangesFormatAWS,
+			body: `{"prefixes":[
+				{"ip_prefix":"3.248.0.0/13","region":"eu-west-1","service":"CLOUDFRONT_ORIGIN_FACING"}
+			]}`,
+			services: []string{"CLOUDFRONT_ORIGIN"},
+			wantErr:  true,
+		},
+		{
+			name:    "gcp region matches nothing",
+			format:  IPRangesFormatGCP,
+			body:    `{"prefixes":[{"ipv4Prefix":"34.1.208.0/20","service":"Google Cloud","scope":"africa-south1"}]}`,
+			regions: []string{"europe-nort1"},
+			wantErr: true,
+		},
+		{
+			name:     "azure service tag matches nothing",
+			format:   IPRangesFormatAzure,
+			body:     `{"values":[{"name":"AzureFrontDoor.Backend","properties":{"addressPrefixes":["147.243.0.0/16"]}}]}`,
+			services: []string{"AzureFrontDoor.Frontend"},
+			wantErr:  true,
+		},
+		{
+			name:     "github key matches nothing",
+			format:   IPRangesFormatGitHub,
+			body:     `{"actions":["4.148.0.0/16"]}`,
+			services: []string{"action"},
+			wantErr:  true,
+		},
+		{
+			name:   "aws without filters",
+			format: IPRangesFormatAWS,
+			body:   `{"prefixes":[]}`,
+			want:   nil,
+		},
+		{
+			name:    "invalid json",
+			format:  IPRangesFormatAWS,
+			body:    `<html>`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid cidr",
+			format:  IPRangesFormatAzure,
+			body:    `{"values":[{"name":"AzureCloud","properties":{"addressPrefixes":["13.69.0.0"]}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported format",
+			format:  "Oracle",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseIPRanges(tt.format, []byte(tt.body), tt.services, tt.regions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseIPRanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseIPRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
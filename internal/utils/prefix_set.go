@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// PrefixSet is a set of IPv4 and IPv6 prefixes. Prefixes returns the minimal list
+// of prefixes covering the set: host bits are cleared, prefixes contained in other
+// prefixes are dropped and adjacent prefixes are merged.
+// The zero value is an empty set.
+type PrefixSet struct {
+	prefixes []netip.Prefix
+}
+
+// NewPrefixSet returns a set containing the given CIDRs.
+func NewPrefixSet(cidrs ...string) (*PrefixSet, error) {
+	set := &PrefixSet{}
+	for _, cidr := range cidrs {
+		if err := set.AddCIDR(cidr); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// AddCIDR adds a CIDR to the set. Host bits are ignored, e.g. "10.0.0.5/24" adds "10.0.0.0/24".
+func (s *PrefixSet) AddCIDR(cidr string) error {
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+	if err != nil {
+		return fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+	s.Add(prefix)
+	return nil
+}
+
+// Add adds a prefix to the set.
+func (s *PrefixSet) Add(prefix netip.Prefix) {
+	s.prefixes = append(s.prefixes, prefix.Masked())
+}
+
+// Prefixes returns the minimal list of prefixes covering the set, sorted numerically
+// with IPv4 before IPv6.
+func (s *PrefixSet) Prefixes() []netip.Prefix {
+
+	sorted := slices.Clone(s.prefixes)
+	slices.SortFunc(sorted, comparePrefixes)
+
+	var aggregated []netip.Prefix
+	for _, prefix := range sorted {
+		// Drop prefixes contained in the previous prefix. Since prefixes are sorted by
+		// address and then by length, a containing prefix always comes first.
+		if n := len(aggregated); n > 0 && aggregated[n-1].Bits() <= prefix.Bits() && aggregated[n-1].Contains(prefix.Addr()) {
+			continue
+		}
+		aggregated = append(aggregated, prefix)
+
+		// Merge adjacent halves of the same parent, which may cascade
+		for n := len(aggregated); n >= 2; n = len(aggregated) {
+			parent, ok := mergePrefixes(aggregated[n-2], aggregated[n-1])
+			if !ok {
+				break
+			}
+			aggregated = append(aggregated[:n-2], parent)
+		}
+	}
+
+	return aggregated
+}
+
+// Strings returns the minimal list of CIDRs covering the set, see Prefixes.
+func (s *PrefixSet) Strings() []string {
+	prefixes := s.Prefixes()
+	cidrs := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		cidrs = append(cidrs, prefix.String())
+	}
+	return cidrs
+}
+
+// comparePrefixes orders prefixes by address, IPv4 before IPv6, and then by length.
+func comparePrefixes(a, b netip.Prefix) int {
+	if c := a.Addr().Compare(b.Addr()); c != 0 {
+		return c
+	}
+	return a.Bits() - b.Bits()
+}
+
+// mergePrefixes returns the parent of a and b if they are the lower and upper half of it.
+func mergePrefixes(a, b netip.Prefix) (netip.Prefix, bool) {
+	if a.Bits() != b.Bits() || a.Bits() == 0 || a.Addr().BitLen() != b.Addr().BitLen() {
+		return netip.Prefix{}, false
+	}
+	parent := netip.PrefixFrom(a.Addr(), a.Bits()-1).Masked()
+	if parent.Addr() != a.Addr() || !parent.Contains(b.Addr()) || a == b {
+		return netip.Prefix{}, false
+	}
+	return parent, true
+}
+
+// Union returns a set containing the prefixes of s and other.
+func (s *PrefixSet) Union(other *PrefixSet) *PrefixSet {
+	union := &PrefixSet{}
+	union.prefixes = append(union.prefixes, s.Prefixes()...)
+	union.prefixes = append(union.prefixes, other.Prefixes()...)
+	return union
+}
+
+// Intersect returns a set containing the addresses that are in both s and other.
+func (s *PrefixSet) Intersect(other *PrefixSet) *PrefixSet {
+	intersection := &PrefixSet{}
+	otherPrefixes := other.Prefixes()
+	for _, a := range s.Prefixes() {
+		for _, b := range otherPrefixes {
+			if !a.Overlaps(b) {
+				continue
+			}
+			// Overlapping prefixes are nested, the longer one is the intersection
+			if a.Bits() >= b.Bits() {
+				intersection.Add(a)
+			} else {
+				intersection.Add(b)
+			}
+		}
+	}
+	return intersection
+}
+
+// Subtract returns a set containing the addresses of s that are not in other.
+func (s *PrefixSet) Subtract(other *PrefixSet) *PrefixSet {
+	difference := &PrefixSet{}
+	otherPrefixes := other.Prefixes()
+	for _, a := range s.Prefixes() {
+		remaining := []netip.Prefix{a}
+		for _, b := range otherPrefixes {
+			if !a.Overlaps(b) {
+				continue
+			}
+			var next []netip.Prefix
+			for _, p := range remaining {
+				next = append(next, subtractPrefix(p, b)...)
+			}
+			remaining = next
+		}
+		difference.prefixes = append(difference.prefixes, remaining...)
+	}
+	return difference
+}
+
+// subtractPrefix returns the prefixes covering p without e. The prefix is split
+// into halves until the halves are either disjoint from or covered by e.
+func subtractPrefix(p netip.Prefix, e netip.Prefix) []netip.Prefix {
+	if !p.Overlaps(e) {
+		return []netip.Prefix{p}
+	}
+	if e.Bits() <= p.Bits() {
+		// e covers p
+		return nil
+	}
+
+	lower, upper := splitPrefix(p)
+	return append(subtractPrefix(lower, e), subtractPrefix(upper, e)...)
+}
+
+// splitPrefix splits p into its two halves.
+func splitPrefix(p netip.Prefix) (netip.Prefix, netip.Prefix) {
+	bits := p.Bits() + 1
+	lower := netip.PrefixFrom(p.Addr(), bits)
+
+	// Set the first host bit of p to get the start of the upper half
+	bytes := p.Addr().AsSlice()
+	bytes[p.Bits()/8] |= 0x80 >> (p.Bits() % 8)
+	upperAddr, _ := netip.AddrFromSlice(bytes)
+
+	return lower, netip.PrefixFrom(upperAddr, bits)
+}
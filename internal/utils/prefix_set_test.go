@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPrefixSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		cidrs []string
+		want  []string
+	}{
+		{
+			name:  "empty",
+			cidrs: nil,
+			want:  []string{},
+		},
+		{
+			name:  "canonicalizes host bits",
+			cidrs: []string{"10.0.0.5/24", "2001:db8::1/64"},
+			want:  []string{"10.0.0.0/24", "2001:db8::/64"},
+		},
+		{
+			name:  "collapses contained prefixes",
+			cidrs: []string{"10.1.2.0/24", "10.0.0.0/8", "10.1.2.3/32", "10.0.0.0/8"},
+			want:  []string{"10.0.0.0/8"},
+		},
+		{
+			name:  "merges adjacent prefixes",
+			cidrs: []string{"192.168.1.0/24", "192.168.0.0/24", "192.168.2.0/23"},
+			want:  []string{"192.168.0.0/22"},
+		},
+		{
+			name:  "does not merge unaligned neighbours",
+			cidrs: []string{"192.168.1.0/24", "192.168.2.0/24"},
+			want:  []string{"192.168.1.0/24", "192.168.2.0/24"},
+		},
+		{
+			name:  "sorts numerically with IPv4 first",
+			cidrs: []string{"2001:db8::/32", "172.16.0.0/12", "9.0.0.0/8", "10.20.30.40/32"},
+			want:  []string{"9.0.0.0/8", "10.20.30.40/32", "172.16.0.0/12", "2001:db8::/32"},
+		},
+		{
+			name:  "merges to the full address space",
+			cidrs: []string{"0.0.0.0/1", "128.0.0.0/1"},
+			want:  []string{"0.0.0.0/0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := NewPrefixSet(tt.cidrs...)
+			if err != nil {
+				t.Fatalf("NewPrefixSet() error = %v", err)
+			}
+			if got := set.Strings(); !slices.Equal(got, tt.want) {
+				t.Errorf("Strings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewPrefixSet("10.0.0.0"); err == nil {
+		t.Error("expected error for invalid CIDR")
+	}
+}
+
+func TestPrefixSetSubtract(t *testing.T) {
+	tests := []struct {
+		name  string
+		cidrs []string
+		other []string
+		want  []string
+	}{
+		{
+			name:  "nothing to subtract",
+			cidrs: []string{"10.0.0.0/8"},
+			want:  []string{"10.0.0.0/8"},
+		},
+		{
+			name:  "splits around the subtracted prefix",
+			cidrs: []string{"10.0.0.0/14"},
+			other: []string{"10.1.0.0/16"},
+			want:  []string{"10.0.0.0/16", "10.2.0.0/15"},
+		},
+		{
+			name:  "subtracted prefix covers the set",
+			cidrs: []string{"10.66.1.0/24"},
+			other: []string{"10.66.0.0/16"},
+			want:  []string{},
+		},
+		{
+			name:  "other prefixes and families are ignored",
+			cidrs: []string{"10.0.0.0/24"},
+			other: []string{"192.168.0.0/16", "2001:db8::/32"},
+			want:  []string{"10.0.0.0/24"},
+		},
+		{
+			name:  "ipv6",
+			cidrs: []string{"2001:db8::/32"},
+			other: []string{"2001:db8:8000::/33"},
+			want:  []string{"2001:db8::/33"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := NewPrefixSet(tt.cidrs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, err := NewPrefixSet(tt.other...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := set.Subtract(other).Strings(); !slices.Equal(got, tt.want) {
+				t.Errorf("Subtract() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}